@@ -1,13 +1,17 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"math/rand"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/luckychess/invasion/scenario"
 	"github.com/luckychess/invasion/simulator"
 	"github.com/luckychess/invasion/world"
 )
@@ -15,23 +19,339 @@ import (
 // program entry point
 func main() {
 	log.SetFlags(0)
+	topologySpec := flag.String("topology", "compass", "directions vocabulary: compass, compass8, hex, cube or a list of opposite pairs like east:west,up:down")
+	headOn := flag.Bool("headon", false, "aliens meeting head-on on the same road fight and destroy the road")
+	movement := flag.String("movement", "simultaneous", "order of alien moves within a step: simultaneous, sequential-random, sequential-sorted or async")
+	continuous := flag.Bool("continuous", false, "use event-driven continuous time engine instead of discrete steps")
+	delaySpec := flag.String("delay", "exp:1", "delay between alien moves for continuous engine: exp:<rate>, uniform:<min>:<max> or fixed:<value>")
+	placementSpec := flag.String("placement", "uniform", "alien placement: uniform, population, degree, distinct, border or cluster:<city>,<city>:<radius>")
+	protected := flag.String("protect", "", "comma separated list of cities where aliens are never placed")
+	rebuild := flag.Uint("rebuild", 0, "amount of steps after which a destroyed city is rebuilt, 0 means never")
+	roadFailure := flag.Float64("road-failure", 0, "probability of every open road to be closed at the beginning of a step")
+	roadRepair := flag.Uint("road-repair", 0, "amount of steps after which a closed road is reopened, 0 means never")
+	policySpec := flag.String("policy", "", "authorities reaction: quarantine or capital:<city>, the result is compared with a run without the policy")
+	blastRadius := flag.Uint("blast-radius", 0, "destroyed cities damage other cities within this amount of roads, 0 disables blasts")
+	hitPoints := flag.Float64("hitpoints", 0, "hit points of cities without hitpoints attribute, 0 means they take no damage")
+	blastRoads := flag.Bool("blast-roads", false, "blasts destroy all the roads within the blast radius")
+	lifespan := flag.Uint("lifespan", 0, "amount of steps aliens live before they die of exhaustion, 0 means forever")
+	fuel := flag.Uint("fuel", 0, "amount of moves aliens are able to make before they are stranded, 0 means unlimited")
+	reproductionSpec := flag.String("reproduction", "", "alien reproduction options: every:<steps>, visit, adjacent and cap:<population> separated by commas")
+	target := flag.String("target", "", "city every alien travels to along the shortest route or random for a random destination of every alien")
+	swarmSpec := flag.String("swarm", "", "aliens sense each other: attract[:<radius>] or repel[:<radius>], the result is compared with a random walk")
+	evacuation := flag.Uint("evacuation", 0, "amount of people able to flee along a road during a step, 0 disables the population layer")
+	evacuationRadius := flag.Uint("evacuation-radius", 0, "people flee from cities within this amount of roads from aliens")
+	infectionSpec := flag.String("infection", "", "aliens occupy cities and convert inhabitants instead of destroying cities: chance:<p>, converts:<n> and recover:<steps> separated by commas")
+	generate := flag.String("generate", "", "write a generated map into the file instead of a simulation: grid, cylinder or torus:<width>x<height>[,holes:<p>]")
+	ascii := flag.Bool("ascii", false, "the map file is drawn as ASCII art with - and | for roads")
+	render := flag.Bool("render", false, "draw the initial and the final map as a grid laid out by road directions")
+	watch := flag.Bool("watch", false, "draw the map after every step as an animation")
+	watchDelay := flag.Duration("watch-delay", 200*time.Millisecond, "pause between frames of the -watch animation")
+	scenarioFile := flag.String("scenario", "", "run a scenario file and check its expectations instead of a random simulation")
+	flag.Parse()
+	if *scenarioFile != "" {
+		runScenario(*scenarioFile)
+		return
+	}
+	if *generate != "" {
+		generateMap(*generate)
+		return
+	}
 	// first argument is amount of alines, second is a file name with cities data
-	if len(os.Args) != 3 {
-		log.Fatalf("Usage: %s <N> <file>, where N is amount of aliens and file is a path to a file with cities data", os.Args[0])
+	if flag.NArg() != 2 {
+		log.Fatalf("Usage: %s [flags] <N> <file>, where N is amount of aliens and file is a path to a file with cities data", os.Args[0])
 	}
-	totalAliens, err := strconv.ParseUint(os.Args[1], 10, 32)
+	totalAliens, err := strconv.ParseUint(flag.Arg(0), 10, 32)
 	if err != nil {
 		log.Fatalf("Command line argument expected to be a non-negative number: %s", err)
 	}
-	lines := readFile(os.Args[2])
-	worldMap := parseInput(lines)
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-	simulator := simulator.InitSimulation(worldMap, rng, uint32(totalAliens))
-	simulator.Simulate()
-	simulationResult := simulator.StopSimulation()
+	topology, err := world.ParseTopology(*topologySpec)
+	if err != nil {
+		log.Fatalf("Wrong topology %s: %s", *topologySpec, err)
+	}
+	lines := readFile(flag.Arg(1))
+	parseMap := world.ParseMap
+	if *ascii {
+		parseMap = world.ParseASCIIMap
+	}
+	worldMap, err := parseMap(lines, topology)
+	if err != nil {
+		log.Fatalf("Error parsing input data: %s", err)
+	}
+	checkGeometry(worldMap)
+	seed := time.Now().UnixNano()
+	rng := rand.New(rand.NewSource(seed))
+	movementOrder, err := simulator.ParseMovementOrder(*movement)
+	if err != nil {
+		log.Fatalf("Wrong movement order: %s", err)
+	}
+	delay, err := simulator.ParseDelay(*delaySpec)
+	if err != nil {
+		log.Fatalf("Wrong delay: %s", err)
+	}
+	placement, err := simulator.ParsePlacement(*placementSpec)
+	if err != nil {
+		log.Fatalf("Wrong placement: %s", err)
+	}
+	rules := simulator.Rules{HeadOnFights: *headOn, Movement: movementOrder, Delay: delay, Placement: placement,
+		RebuildAfter: uint32(*rebuild), RoadFailure: *roadFailure, RoadRepairAfter: uint32(*roadRepair), Target: *target,
+		Blast:      simulator.Blast{Radius: uint32(*blastRadius), HitPoints: *hitPoints, DestroyRoads: *blastRoads},
+		Evacuation: simulator.Evacuation{Capacity: uint32(*evacuation), Radius: uint32(*evacuationRadius)}}
+	if *protected != "" {
+		rules.Protected = strings.Split(*protected, ",")
+	}
+	if *continuous {
+		rules.Engine = simulator.ContinuousEngine
+	}
+	if *lifespan > 0 || *fuel > 0 {
+		rules.Vitals = map[string]simulator.Vitals{"": {Lifespan: uint32(*lifespan), Fuel: uint32(*fuel)}}
+	}
+	if *reproductionSpec != "" {
+		if rules.Reproduction, err = simulator.ParseReproduction(*reproductionSpec); err != nil {
+			log.Fatalf("Wrong reproduction: %s", err)
+		}
+	}
+	if *infectionSpec != "" {
+		if rules.Infection, err = simulator.ParseInfection(*infectionSpec); err != nil {
+			log.Fatalf("Wrong infection: %s", err)
+		}
+	}
+	if *swarmSpec != "" {
+		if rules.Swarm, err = simulator.ParseSwarm(*swarmSpec); err != nil {
+			log.Fatalf("Wrong swarm: %s", err)
+		}
+	}
+	if *policySpec != "" {
+		if rules.Policy, err = simulator.ParsePolicy(*policySpec); err != nil {
+			log.Fatalf("Wrong policy: %s", err)
+		}
+	}
+	var baseline world.WorldMap
+	if rules.Policy != nil {
+		// the same simulation without the policy is performed first to measure the policy effect
+		baseline, err = parseMap(lines, topology)
+		if err != nil {
+			log.Fatalf("Error parsing input data: %s", err)
+		}
+		baselineRules := rules
+		baselineRules.Policy = nil
+		baselineSim := simulator.InitSimulation(baseline, rand.New(rand.NewSource(seed)), uint32(totalAliens))
+		baselineSim.SetRules(baselineRules)
+		log.Println("=== Baseline simulation without policy ===")
+		baselineSim.Simulate()
+	}
+	var walk *simulator.Result
+	if rules.Swarm.Mode != simulator.NoSwarm {
+		// the same simulation with a random walk is performed to compare destruction rates
+		walkMap, err := parseMap(lines, topology)
+		if err != nil {
+			log.Fatalf("Error parsing input data: %s", err)
+		}
+		walkRules := rules
+		walkRules.Swarm = simulator.Swarm{}
+		walkSim := simulator.InitSimulation(walkMap, rand.New(rand.NewSource(seed)), uint32(totalAliens))
+		walkSim.SetRules(walkRules)
+		log.Println("=== Random walk simulation ===")
+		walkResult := walkSim.Simulate()
+		walk = &walkResult
+	}
+	sim := simulator.InitSimulation(worldMap, rng, uint32(totalAliens))
+	sim.SetRules(rules)
+	if *watch {
+		sim.SetObserver(func(step uint32) {
+			// clear the terminal before every frame
+			fmt.Print("\033[H\033[2J")
+			fmt.Printf("Step %d\n", step)
+			printMap(worldMap)
+			time.Sleep(*watchDelay)
+		})
+	} else if *render {
+		sim.SetObserver(func(step uint32) {
+			if step == 0 {
+				printMap(worldMap)
+			}
+		})
+	}
+	result := sim.Simulate()
+	if *render && !*watch {
+		printMap(worldMap)
+	}
+	log.Printf("Simulation stopped at time %g: %s, %d cities and %d roads destroyed, %d cities collapsed",
+		result.Time, result.Reason, result.Count(simulator.CityDestroyed), result.Count(simulator.RoadDestroyed), result.Count(simulator.CityCollapsed))
+	printCycles(result)
+	printGoals(result)
+	if rules.Evacuation.Capacity > 0 {
+		printPopulation(result)
+	}
+	printEpidemic(result)
+	if born := result.Count(simulator.AlienBorn); born > 0 {
+		log.Printf("%d aliens have been born", born)
+	}
+	if rules.Vitals != nil {
+		log.Printf("%d aliens died of exhaustion and %d ran out of fuel", result.Count(simulator.AlienExhausted), result.Count(simulator.AlienStranded))
+	}
+	if baseline != nil {
+		effect := simulator.ComparePolicy(baseline, worldMap)
+		log.Printf("Policy has saved %d cities %s and lost %d cities %s compared to the baseline",
+			len(effect.Saved), strings.Join(effect.Saved, " "), len(effect.Lost), strings.Join(effect.Lost, " "))
+	}
+	if walk != nil {
+		log.Printf("Swarm has destroyed %d cities in %d steps (%.3f per step), random walk has destroyed %d cities in %d steps (%.3f per step)",
+			result.Count(simulator.CityDestroyed), result.Steps, result.DestructionRate(),
+			walk.Count(simulator.CityDestroyed), walk.Steps, walk.DestructionRate())
+	}
+	if trapped := world.TrappedAliens(worldMap); len(trapped) > 0 {
+		log.Printf("Aliens trapped in cities without open roads: %s", strings.Join(trapped, " "))
+	}
+	simulationResult := sim.StopSimulation()
 	log.Print(simulationResult)
 }
 
+// runScenario performs a simulation described by the scenario file and
+// exits with non-zero code if any of its expectations fails.
+func runScenario(fileName string) {
+	setup, err := scenario.Load(fileName)
+	if err != nil {
+		log.Fatalf("Error loading scenario: %s", err)
+	}
+	worldMap, result, err := setup.Run()
+	if err != nil {
+		log.Fatalf("Error running scenario: %s", err)
+	}
+	log.Printf("Simulation stopped at time %g: %s", result.Time, result.Reason)
+	printCycles(result)
+	printGoals(result)
+	if setup.Rules.Evacuation.Capacity > 0 {
+		printPopulation(result)
+	}
+	printEpidemic(result)
+	if setup.Rules.Policy != nil {
+		baseline, _, err := setup.RunBaseline()
+		if err != nil {
+			log.Fatalf("Error running baseline: %s", err)
+		}
+		effect := simulator.ComparePolicy(baseline, worldMap)
+		log.Printf("Policy has saved %d cities and lost %d cities compared to the baseline", len(effect.Saved), len(effect.Lost))
+	}
+	for _, defender := range result.Defenders {
+		status := "is in " + defender.City
+		if defender.Fallen {
+			status = "has fallen in " + defender.City
+		}
+		log.Printf("Defender %s %s and has killed %d aliens", defender.Name, status, defender.Kills)
+	}
+	credits := result.DestructionByWave()
+	waves := make([]string, 0, len(credits))
+	for wave := range credits {
+		waves = append(waves, wave)
+	}
+	sort.Strings(waves)
+	for _, wave := range waves {
+		log.Printf("Wave %s destroyed %d cities and roads", wave, credits[wave])
+	}
+	log.Print("=== Simulation finished ===\n" + world.WriteMap(worldMap))
+	failures := setup.Check(worldMap, result)
+	for _, failure := range failures {
+		log.Printf("FAILED: %s", failure)
+	}
+	if len(failures) > 0 {
+		os.Exit(1)
+	}
+	log.Printf("All %d expectations are met", len(setup.Expectations))
+}
+
+// generateMap writes a generated grid world into the file given as the only argument.
+func generateMap(spec string) {
+	if flag.NArg() != 1 {
+		log.Fatalf("Usage: %s -generate <spec> <file>, where file is a path to write cities data to", os.Args[0])
+	}
+	grid, err := world.ParseGrid(spec)
+	if err != nil {
+		log.Fatalf("Wrong grid: %s", err)
+	}
+	worldMap, err := world.GenerateGrid(grid, rand.New(rand.NewSource(time.Now().UnixNano())))
+	if err != nil {
+		log.Fatalf("Error generating map: %s", err)
+	}
+	if err := os.WriteFile(flag.Arg(0), []byte(world.WriteMap(worldMap)), 0644); err != nil {
+		log.Fatalf("Error writing map: %s", err)
+	}
+	log.Printf("Map of %d cities has been written to %s", len(worldMap.GetCities()), flag.Arg(0))
+}
+
+// checkGeometry warns about roads contradicting the grid layout of the map.
+func checkGeometry(worldMap world.WorldMap) {
+	geometry, err := world.InferGeometry(worldMap)
+	if err != nil {
+		// maps with other directions have no grid geometry to check
+		return
+	}
+	for _, contradiction := range geometry.Contradictions {
+		log.Printf("Warning: map geometry is inconsistent, %s", contradiction)
+	}
+}
+
+// printMap draws the world map into the standard output.
+func printMap(worldMap world.WorldMap) {
+	drawing, err := world.Render(worldMap)
+	if err != nil {
+		log.Printf("The map can't be drawn: %s", err)
+		return
+	}
+	fmt.Print(drawing)
+}
+
+// printCycles reports cities which have been rebuilt after destruction.
+func printCycles(result simulator.Result) {
+	cycles := result.CityCycles()
+	cities := make([]string, 0, len(cycles))
+	for city, cycle := range cycles {
+		if cycle.Rebuilt > 0 {
+			cities = append(cities, city)
+		}
+	}
+	sort.Strings(cities)
+	for _, city := range cities {
+		log.Printf("%s has been destroyed %d times and rebuilt %d times", city, cycles[city].Destroyed, cycles[city].Rebuilt)
+	}
+}
+
+// printGoals reports whether aliens travelling to targets have reached them.
+func printGoals(result simulator.Result) {
+	reached := 0
+	for _, goal := range result.Goals {
+		status := "has not reached"
+		if goal.Reached {
+			status = "has reached"
+			reached++
+		}
+		log.Printf("Alien %s %s its target %s, the route has been planned again %d times", goal.Alien, status, goal.Target, goal.Replans)
+	}
+	if len(result.Goals) > 0 {
+		log.Printf("%d of %d aliens have reached their targets", reached, len(result.Goals))
+	}
+}
+
+// printPopulation reports lives lost and saved.
+func printPopulation(result simulator.Result) {
+	population := result.Population
+	log.Printf("%d of %d people have been lost and %d saved, %d people have been evacuated counting every escape",
+		population.Lost, population.Initial, population.Saved, population.Evacuated)
+}
+
+// printEpidemic prints amount of susceptible, occupied and recovered cities for every step they change.
+func printEpidemic(result simulator.Result) {
+	for i, sir := range result.Epidemic {
+		if i > 0 && i < len(result.Epidemic)-1 && sir.Susceptible == result.Epidemic[i-1].Susceptible &&
+			sir.Occupied == result.Epidemic[i-1].Occupied && sir.Recovered == result.Epidemic[i-1].Recovered {
+			continue
+		}
+		log.Printf("Step %d: %d susceptible, %d occupied, %d recovered cities", sir.Step, sir.Susceptible, sir.Occupied, sir.Recovered)
+	}
+	if converted := result.Count(simulator.AlienConverted); converted > 0 {
+		log.Printf("%d inhabitants have been converted into aliens", converted)
+	}
+}
+
 func readFile(fileName string) []string {
 	// read all the file at once
 	// it's probably more efficient to read line by line and
@@ -47,44 +367,3 @@ func readFile(fileName string) []string {
 	}
 	return inputLines
 }
-
-func parseInput(lines []string) world.WorldMap {
-	/*
-		Sample file data:
-		------------------
-		Foo north=Bar west=Baz south=Qu-ux
-		Bar south=Foo west=Bee
-	*/
-	worldMap := world.InitWorldMap()
-
-	for _, line := range lines {
-		// expect every line data is separated by spaces
-		words := strings.Split(line, " ")
-		// first word is always a city name (shouldn't contain spaces)
-		newCity := words[0]
-		var east, north, west, south string
-		// expect city1=city2 pairs, up to 4 pairs, one pair for every direction {east, north, west, south}
-		for i := 1; i < len(words); i++ {
-			road := strings.Split(words[i], "=")
-			if len(road) != 2 {
-				log.Fatalf("Error parsing input data: expected city1=city2 format but got %s", words[i])
-			}
-			direction, city := road[0], road[1]
-			switch direction {
-			case "east":
-				east = city
-			case "north":
-				north = city
-			case "west":
-				west = city
-			case "south":
-				south = city
-			default:
-				log.Fatalf("Error parsing input data: wrong direction %s", direction)
-			}
-
-		}
-		worldMap.AddCity(newCity, east, north, west, south)
-	}
-	return worldMap
-}
@@ -0,0 +1,152 @@
+package scenario
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/luckychess/invasion/simulator"
+	"github.com/luckychess/invasion/world"
+)
+
+// Expectation is a single check of the simulation outcome, e.g. "destroyed Foo".
+type Expectation struct {
+	Kind      string
+	Arguments []string
+}
+
+func (e Expectation) String() string {
+	return strings.TrimSpace(e.Kind + " " + strings.Join(e.Arguments, " "))
+}
+
+// expectationArguments keeps amount of arguments of every expectation kind, -1 means any amount.
+var expectationArguments = map[string]int{
+	"destroyed":  1,
+	"survives":   1,
+	"alive":      1,
+	"dead":       1,
+	"aliens":     1,
+	"cities":     1,
+	"steps":      1,
+	"reason":     -1,
+	"events":     -1,
+	"credit":     2,
+	"rebuilt":    2,
+	"trapped":    1,
+	"defenders":  1,
+	"kills":      2,
+	"saved":      1,
+	"lost":       1,
+	"reached":    1,
+	"casualties": 1,
+	"survivors":  1,
+	"occupied":   1,
+	"recovered":  1,
+}
+
+func parseExpectation(words []string) (Expectation, error) {
+	if len(words) == 0 {
+		return Expectation{}, fmt.Errorf("expected expect <kind> [arguments...]")
+	}
+	expectation := Expectation{Kind: words[0], Arguments: words[1:]}
+	arguments, ok := expectationArguments[expectation.Kind]
+	if !ok {
+		return expectation, fmt.Errorf("unknown expectation %s", expectation.Kind)
+	}
+	if arguments >= 0 && len(expectation.Arguments) != arguments {
+		return expectation, fmt.Errorf("expectation %s requires %d argument(s)", expectation.Kind, arguments)
+	}
+	if expectation.Kind == "events" && len(expectation.Arguments) < 2 {
+		return expectation, fmt.Errorf("expected events <count> <event type>")
+	}
+	return expectation, nil
+}
+
+// check verifies the expectation, effect of the policy is required only for saved and lost expectations.
+func (e Expectation) check(worldMap world.WorldMap, result simulator.Result, effect *simulator.PolicyEffect) error {
+	var actual string
+	expected := strings.Join(e.Arguments, " ")
+	switch e.Kind {
+	case "destroyed", "survives":
+		if _, exists := worldMap.GetCities()[e.Arguments[0]]; exists == (e.Kind == "destroyed") {
+			return fmt.Errorf("expected %s", e)
+		}
+		return nil
+	case "alive", "dead":
+		if _, exists := worldMap.GetAliens()[e.Arguments[0]]; exists == (e.Kind == "dead") {
+			return fmt.Errorf("expected %s", e)
+		}
+		return nil
+	case "aliens":
+		actual = strconv.Itoa(len(worldMap.GetAliens()))
+	case "cities":
+		actual = strconv.Itoa(len(worldMap.GetCities()))
+	case "steps":
+		actual = strconv.Itoa(int(result.Steps))
+	case "reason":
+		actual = result.Reason.String()
+	case "events":
+		eventType := strings.Join(e.Arguments[1:], " ")
+		count := 0
+		for _, event := range result.Events {
+			if event.Type.String() == eventType {
+				count++
+			}
+		}
+		expected = e.Arguments[0]
+		actual = strconv.Itoa(count)
+	case "credit":
+		expected = e.Arguments[1]
+		actual = strconv.Itoa(result.DestructionByWave()[e.Arguments[0]])
+	case "trapped":
+		actual = strconv.Itoa(len(world.TrappedAliens(worldMap)))
+	case "defenders":
+		alive := 0
+		for _, defender := range result.Defenders {
+			if !defender.Fallen {
+				alive++
+			}
+		}
+		actual = strconv.Itoa(alive)
+	case "kills":
+		expected = e.Arguments[1]
+		actual = "no such defender"
+		for _, defender := range result.Defenders {
+			if defender.Name == e.Arguments[0] {
+				actual = strconv.Itoa(int(defender.Kills))
+			}
+		}
+	case "saved":
+		actual = strconv.Itoa(len(effect.Saved))
+	case "lost":
+		actual = strconv.Itoa(len(effect.Lost))
+	case "reached":
+		reached := 0
+		for _, goal := range result.Goals {
+			if goal.Reached {
+				reached++
+			}
+		}
+		actual = strconv.Itoa(reached)
+	case "casualties":
+		actual = strconv.FormatUint(result.Population.Lost, 10)
+	case "survivors":
+		actual = strconv.FormatUint(result.Population.Saved, 10)
+	case "occupied", "recovered":
+		sir := simulator.SIR{}
+		if len(result.Epidemic) > 0 {
+			sir = result.Epidemic[len(result.Epidemic)-1]
+		}
+		actual = strconv.Itoa(sir.Occupied)
+		if e.Kind == "recovered" {
+			actual = strconv.Itoa(sir.Recovered)
+		}
+	case "rebuilt":
+		expected = e.Arguments[1]
+		actual = strconv.Itoa(result.CityCycles()[e.Arguments[0]].Rebuilt)
+	}
+	if actual != expected {
+		return fmt.Errorf("expected %s but got %s", e, actual)
+	}
+	return nil
+}
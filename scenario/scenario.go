@@ -0,0 +1,488 @@
+// Package scenario describes an exact initial setup of the simulation together with
+// its expected outcome, so deterministic runs can be checked into the repository.
+package scenario
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/luckychess/invasion/simulator"
+	"github.com/luckychess/invasion/world"
+)
+
+/*
+	Sample scenario file:
+	------------------
+	# comments and empty lines are ignored
+	map input.txt
+	format text
+	topology compass
+	seed 42
+	steps 100
+	aliens 2
+	rule movement sequential-sorted
+	rule headon on
+	rule rebuild 20
+	rule roadfailure 0.01
+	rule roadrepair 5
+	rule policy capital:Bar
+	rule blast 2
+	rule hitpoints 5
+	rule blastroads on
+	rule lifespan 500
+	rule fuel grey:100
+	rule reproduction every:10,adjacent,cap:50
+	rule target Bar
+	rule swarm attract:2
+	rule evacuation 100
+	rule evacradius 1
+	rule infection chance:0.5,recover:10
+	alien Zorg Foo species=grey
+	defender Knight Bar strength=2 strategy=hunt
+	at 5 spawn Blorg Bar
+	at 10 every 20 times 3 wave reinforcements 4 Foo,Bar
+	at 3 close Foo north
+	at 8 reopen Foo north
+	expect destroyed Foo
+	expect credit reinforcements 1
+	expect rebuilt Foo 1
+	expect trapped 0
+	expect kills Knight 1
+	expect saved 2
+	expect reached 1
+	expect casualties 0
+	expect occupied 3
+	expect reason no aliens left
+*/
+
+// Scenario contains everything required to run a reproducible simulation.
+type Scenario struct {
+	// MapFile is a path to the map, relative paths are resolved against the scenario file.
+	MapFile string
+	// ASCIIMap tells that the map is drawn as ASCII art.
+	ASCIIMap bool
+	Topology *world.Topology
+	Seed     int64
+	Steps    uint32
+	// RandomAliens is amount of unnamed aliens placed according to the placement rule.
+	RandomAliens uint32
+	Rules        simulator.Rules
+	Aliens       []world.Alien
+	Defenders    []simulator.Defender
+	Actions      []ScheduledAction
+	Expectations []Expectation
+	// baseline keeps the outcome of RunBaseline, so the baseline is simulated only once
+	baseline *outcome
+}
+
+// outcome is a finished simulation.
+type outcome struct {
+	worldMap world.WorldMap
+	result   simulator.Result
+	err      error
+}
+
+// ScheduledAction is an action which happens at the beginning of the given step.
+// If Every is set, the action repeats with this period Times times or until the end
+// of the simulation if Times is 0.
+type ScheduledAction struct {
+	Step   uint32
+	Every  uint32
+	Times  uint32
+	Action simulator.Action
+}
+
+// Load reads and parses a scenario file.
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	scenario, err := Parse(strings.Split(string(data), "\n"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if !filepath.IsAbs(scenario.MapFile) {
+		scenario.MapFile = filepath.Join(filepath.Dir(path), scenario.MapFile)
+	}
+	return scenario, nil
+}
+
+// Parse creates a scenario from lines of the scenario file.
+func Parse(lines []string) (*Scenario, error) {
+	scenario := &Scenario{Topology: world.CompassTopology, Steps: 10000}
+	for i, line := range lines {
+		words := strings.Fields(line)
+		if len(words) == 0 || strings.HasPrefix(words[0], "#") {
+			continue
+		}
+		if err := scenario.parseLine(words); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+	if scenario.MapFile == "" {
+		return nil, fmt.Errorf("map file is not set")
+	}
+	return scenario, nil
+}
+
+func (s *Scenario) parseLine(words []string) error {
+	var err error
+	switch words[0] {
+	case "map":
+		if len(words) != 2 {
+			return fmt.Errorf("expected map <file>")
+		}
+		s.MapFile = words[1]
+	case "format":
+		if len(words) != 2 || words[1] != "text" && words[1] != "ascii" {
+			return fmt.Errorf("expected format text or format ascii")
+		}
+		s.ASCIIMap = words[1] == "ascii"
+	case "topology":
+		if len(words) != 2 {
+			return fmt.Errorf("expected topology <spec>")
+		}
+		s.Topology, err = world.ParseTopology(words[1])
+	case "seed":
+		if len(words) != 2 {
+			return fmt.Errorf("expected seed <number>")
+		}
+		s.Seed, err = strconv.ParseInt(words[1], 10, 64)
+	case "steps":
+		if len(words) != 2 {
+			return fmt.Errorf("expected steps <number>")
+		}
+		s.Steps, err = parseUint32(words[1])
+	case "aliens":
+		if len(words) != 2 {
+			return fmt.Errorf("expected aliens <number>")
+		}
+		s.RandomAliens, err = parseUint32(words[1])
+	case "rule":
+		if len(words) != 3 {
+			return fmt.Errorf("expected rule <name> <value>")
+		}
+		err = parseRule(&s.Rules, words[1], words[2])
+	case "alien":
+		if len(words) < 3 {
+			return fmt.Errorf("expected alien <name> <city> [key=value...]")
+		}
+		var attributes map[string]string
+		attributes, err = parseAttributes(words[3:])
+		s.Aliens = append(s.Aliens, world.Alien{Name: words[1], City: words[2], Attributes: attributes})
+	case "defender":
+		err = s.parseDefender(words[1:])
+	case "at":
+		err = s.parseAction(words[1:])
+	case "expect":
+		var expectation Expectation
+		expectation, err = parseExpectation(words[1:])
+		s.Expectations = append(s.Expectations, expectation)
+	default:
+		return fmt.Errorf("unknown keyword %s", words[0])
+	}
+	return err
+}
+
+func (s *Scenario) parseDefender(words []string) error {
+	if len(words) < 2 {
+		return fmt.Errorf("expected defender <name> <city> [strength=<n>] [strategy=<name>]")
+	}
+	attributes, err := parseAttributes(words[2:])
+	if err != nil {
+		return err
+	}
+	defender := simulator.Defender{Name: words[0], City: words[1]}
+	for key, value := range attributes {
+		switch key {
+		case "strength":
+			defender.Strength, err = parseUint32(value)
+		case "strategy":
+			defender.Strategy, err = simulator.ParseDefenderStrategy(value)
+		default:
+			err = fmt.Errorf("unknown defender attribute %s", key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	s.Defenders = append(s.Defenders, defender)
+	return nil
+}
+
+func (s *Scenario) parseAction(words []string) error {
+	if len(words) < 2 {
+		return fmt.Errorf("expected at <step> [every <period> [times <n>]] <action> [arguments...]")
+	}
+	step, err := parseUint32(words[0])
+	if err != nil {
+		return err
+	}
+	scheduled := ScheduledAction{Step: step}
+	words = words[1:]
+	if words[0] == "every" {
+		if len(words) < 3 {
+			return fmt.Errorf("expected every <period> <action>")
+		}
+		if scheduled.Every, err = parseUint32(words[1]); err != nil {
+			return err
+		}
+		if scheduled.Every == 0 {
+			return fmt.Errorf("period should be positive")
+		}
+		words = words[2:]
+		if words[0] == "times" {
+			if len(words) < 3 {
+				return fmt.Errorf("expected times <n> <action>")
+			}
+			if scheduled.Times, err = parseUint32(words[1]); err != nil {
+				return err
+			}
+			words = words[2:]
+		}
+	}
+	switch words[0] {
+	case "spawn":
+		if len(words) < 3 {
+			return fmt.Errorf("expected spawn <name> <city> [key=value...]")
+		}
+		attributes, err := parseAttributes(words[3:])
+		if err != nil {
+			return err
+		}
+		scheduled.Action = simulator.SpawnAlien{Name: words[1], City: words[2], Attributes: attributes}
+	case "wave":
+		if len(words) < 3 || len(words) > 4 {
+			return fmt.Errorf("expected wave <name> <count> [city,city...]")
+		}
+		count, err := parseUint32(words[2])
+		if err != nil {
+			return err
+		}
+		wave := simulator.Wave{Name: words[1], Count: count}
+		if len(words) == 4 {
+			wave.Cities = strings.Split(words[3], ",")
+		}
+		scheduled.Action = wave
+	case "rebuild":
+		if len(words) != 2 {
+			return fmt.Errorf("expected rebuild <city>")
+		}
+		scheduled.Action = simulator.Rebuild{City: words[1]}
+	case "close", "reopen":
+		if len(words) != 3 {
+			return fmt.Errorf("expected %s <city> <direction>", words[0])
+		}
+		if words[0] == "close" {
+			scheduled.Action = simulator.CloseRoad{City: words[1], Direction: words[2]}
+		} else {
+			scheduled.Action = simulator.ReopenRoad{City: words[1], Direction: words[2]}
+		}
+	default:
+		return fmt.Errorf("unknown action %s", words[0])
+	}
+	s.Actions = append(s.Actions, scheduled)
+	return nil
+}
+
+func parseRule(rules *simulator.Rules, name string, value string) error {
+	var err error
+	switch name {
+	case "headon":
+		rules.HeadOnFights, err = parseSwitch(value)
+	case "movement":
+		rules.Movement, err = simulator.ParseMovementOrder(value)
+	case "engine":
+		switch value {
+		case "discrete":
+			rules.Engine = simulator.DiscreteEngine
+		case "continuous":
+			rules.Engine = simulator.ContinuousEngine
+		default:
+			err = fmt.Errorf("unknown engine %s", value)
+		}
+	case "delay":
+		rules.Delay, err = simulator.ParseDelay(value)
+	case "placement":
+		rules.Placement, err = simulator.ParsePlacement(value)
+	case "protect":
+		rules.Protected = strings.Split(value, ",")
+	case "rebuild":
+		rules.RebuildAfter, err = parseUint32(value)
+	case "roadfailure":
+		rules.RoadFailure, err = strconv.ParseFloat(value, 64)
+		if err != nil || rules.RoadFailure < 0 || rules.RoadFailure > 1 {
+			err = fmt.Errorf("expected probability between 0 and 1 but got %s", value)
+		}
+	case "roadrepair":
+		rules.RoadRepairAfter, err = parseUint32(value)
+	case "policy":
+		rules.Policy, err = simulator.ParsePolicy(value)
+	case "blast":
+		rules.Blast.Radius, err = parseUint32(value)
+	case "hitpoints":
+		rules.Blast.HitPoints, err = strconv.ParseFloat(value, 64)
+		if err != nil || rules.Blast.HitPoints < 0 {
+			err = fmt.Errorf("expected non-negative hit points but got %s", value)
+		}
+	case "blastroads":
+		rules.Blast.DestroyRoads, err = parseSwitch(value)
+	case "lifespan", "fuel":
+		err = parseVital(rules, name, value)
+	case "reproduction":
+		rules.Reproduction, err = simulator.ParseReproduction(value)
+	case "target":
+		rules.Target = value
+	case "swarm":
+		rules.Swarm, err = simulator.ParseSwarm(value)
+	case "evacuation":
+		rules.Evacuation.Capacity, err = parseUint32(value)
+	case "evacradius":
+		rules.Evacuation.Radius, err = parseUint32(value)
+	case "infection":
+		rules.Infection, err = simulator.ParseInfection(value)
+	default:
+		err = fmt.Errorf("unknown rule %s", name)
+	}
+	return err
+}
+
+// Run loads the map and performs the simulation.
+func (s *Scenario) Run() (world.WorldMap, simulator.Result, error) {
+	return s.run(s.Rules)
+}
+
+// RunBaseline performs the same simulation without the policy, so the effect of the policy
+// can be measured. The baseline is simulated on the first call and reused afterwards.
+func (s *Scenario) RunBaseline() (world.WorldMap, simulator.Result, error) {
+	if s.baseline == nil {
+		rules := s.Rules
+		rules.Policy = nil
+		worldMap, result, err := s.run(rules)
+		s.baseline = &outcome{worldMap: worldMap, result: result, err: err}
+	}
+	return s.baseline.worldMap, s.baseline.result, s.baseline.err
+}
+
+func (s *Scenario) run(rules simulator.Rules) (world.WorldMap, simulator.Result, error) {
+	data, err := os.ReadFile(s.MapFile)
+	if err != nil {
+		return nil, simulator.Result{}, err
+	}
+	parseMap := world.ParseMap
+	if s.ASCIIMap {
+		parseMap = world.ParseASCIIMap
+	}
+	worldMap, err := parseMap(strings.Split(string(data), "\n"), s.Topology)
+	if err != nil {
+		return nil, simulator.Result{}, fmt.Errorf("%s: %w", s.MapFile, err)
+	}
+	sim := simulator.InitSimulation(worldMap, rand.New(rand.NewSource(s.Seed)), s.RandomAliens)
+	sim.SetStepLimit(s.Steps)
+	sim.SetRules(rules)
+	for _, defender := range s.Defenders {
+		if err := sim.AddDefender(defender); err != nil {
+			return nil, simulator.Result{}, err
+		}
+	}
+	// named aliens arrive right after random ones and belong to the initial wave unless told otherwise
+	for _, alien := range s.Aliens {
+		if worldMap.GetCities()[alien.City] == nil {
+			return nil, simulator.Result{}, fmt.Errorf("alien %s is placed into non-existing city %s", alien.Name, alien.City)
+		}
+		attributes := map[string]string{"wave": "initial"}
+		for key, value := range alien.Attributes {
+			attributes[key] = value
+		}
+		sim.Schedule(0, simulator.SpawnAlien{Name: alien.Name, City: alien.City, Attributes: attributes})
+	}
+	for _, action := range s.Actions {
+		if action.Every > 0 {
+			sim.SchedulePeriodic(action.Step, action.Every, action.Times, action.Action)
+		} else {
+			sim.Schedule(action.Step, action.Action)
+		}
+	}
+	return worldMap, sim.Simulate(), nil
+}
+
+// Check compares the outcome of the simulation with expectations of the scenario
+// and returns all the failed ones. Expectations about the policy effect compare the map
+// with the baseline simulation without the policy.
+func (s *Scenario) Check(worldMap world.WorldMap, result simulator.Result) []error {
+	failures := make([]error, 0)
+	var effect *simulator.PolicyEffect
+	for _, expectation := range s.Expectations {
+		if (expectation.Kind == "saved" || expectation.Kind == "lost") && effect == nil {
+			baseline, _, err := s.RunBaseline()
+			if err != nil {
+				failures = append(failures, err)
+				continue
+			}
+			comparison := simulator.ComparePolicy(baseline, worldMap)
+			effect = &comparison
+		}
+		if err := expectation.check(worldMap, result, effect); err != nil {
+			failures = append(failures, err)
+		}
+	}
+	return failures
+}
+
+// parseVital parses a vital limit for all the aliens, e.g. "20", or for a species, e.g. "grey:20".
+func parseVital(rules *simulator.Rules, name string, value string) error {
+	species := ""
+	if separator := strings.LastIndex(value, ":"); separator >= 0 {
+		species, value = value[:separator], value[separator+1:]
+	}
+	limit, err := parseUint32(value)
+	if err != nil {
+		return err
+	}
+	if rules.Vitals == nil {
+		rules.Vitals = make(map[string]simulator.Vitals)
+	}
+	vitals := rules.Vitals[species]
+	if name == "lifespan" {
+		vitals.Lifespan = limit
+	} else {
+		vitals.Fuel = limit
+	}
+	rules.Vitals[species] = vitals
+	return nil
+}
+
+func parseAttributes(words []string) (map[string]string, error) {
+	attributes := make(map[string]string)
+	for _, word := range words {
+		keyValue := strings.Split(word, "=")
+		if len(keyValue) != 2 || keyValue[0] == "" {
+			return nil, fmt.Errorf("expected key=value attribute but got %s", word)
+		}
+		attributes[keyValue[0]] = keyValue[1]
+	}
+	return attributes, nil
+}
+
+func parseSwitch(value string) (bool, error) {
+	switch value {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("expected on or off but got %s", value)
+}
+
+func parseUint32(value string) (uint32, error) {
+	number, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("expected a non-negative number but got %s", value)
+	}
+	return uint32(number), nil
+}
@@ -0,0 +1,207 @@
+package scenario
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/luckychess/invasion/simulator"
+	"github.com/luckychess/invasion/world"
+	"gotest.tools/v3/assert"
+)
+
+func TestParse(t *testing.T) {
+	scenario, err := Parse([]string{
+		"# comment",
+		"map world.txt",
+		"format ascii",
+		"topology hex",
+		"seed 7",
+		"steps 50",
+		"aliens 3",
+		"",
+		"rule headon on",
+		"rule movement sequential-random",
+		"rule engine continuous",
+		"rule delay fixed:2",
+		"rule placement border",
+		"rule protect A,B",
+		"rule rebuild 12",
+		"rule roadfailure 0.25",
+		"rule roadrepair 3",
+		"rule policy quarantine",
+		"rule blast 2",
+		"rule hitpoints 3.5",
+		"rule blastroads on",
+		"rule lifespan 20",
+		"rule lifespan grey:5",
+		"rule fuel grey:3",
+		"rule reproduction visit,cap:9",
+		"rule target random",
+		"rule swarm repel:3",
+		"rule evacuation 50",
+		"rule evacradius 2",
+		"rule infection chance:0.2,converts:2",
+		"alien Zorg Foo species=grey",
+		"defender Knight Bar strength=2 strategy=hunt",
+		"defender Guard Foo",
+		"at 5 spawn Blorg Bar",
+		"at 10 every 20 times 3 wave reinforcements 4 Foo,Bar",
+		"at 1 every 5 wave trickle 1",
+		"at 7 rebuild Foo",
+		"at 2 close Foo east",
+		"at 4 reopen Foo east",
+		"expect destroyed Foo",
+		"expect events 2 city destroyed",
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, scenario.MapFile == "world.txt")
+	assert.Assert(t, scenario.ASCIIMap)
+	assert.Assert(t, scenario.Topology == world.HexTopology)
+	assert.Assert(t, scenario.Seed == 7)
+	assert.Assert(t, scenario.Steps == 50)
+	assert.Assert(t, scenario.RandomAliens == 3)
+	assert.DeepEqual(t, scenario.Rules, simulator.Rules{
+		HeadOnFights:    true,
+		Movement:        simulator.SequentialRandom,
+		Engine:          simulator.ContinuousEngine,
+		Delay:           simulator.FixedDelay{Value: 2},
+		Placement:       simulator.BorderPlacement{},
+		Protected:       []string{"A", "B"},
+		RebuildAfter:    12,
+		RoadFailure:     0.25,
+		RoadRepairAfter: 3,
+		Policy:          simulator.QuarantinePolicy{},
+		Blast:           simulator.Blast{Radius: 2, HitPoints: 3.5, DestroyRoads: true},
+		Vitals:          map[string]simulator.Vitals{"": {Lifespan: 20}, "grey": {Lifespan: 5, Fuel: 3}},
+		Reproduction:    simulator.Reproduction{OnVisit: true, Cap: 9},
+		Target:          simulator.RandomTarget,
+		Swarm:           simulator.Swarm{Mode: simulator.Repulsion, Radius: 3},
+		Evacuation:      simulator.Evacuation{Capacity: 50, Radius: 2},
+		Infection:       simulator.Infection{Probability: 0.2, Converts: 2},
+	})
+	assert.DeepEqual(t, scenario.Aliens, []world.Alien{{Name: "Zorg", City: "Foo", Attributes: map[string]string{"species": "grey"}}})
+	assert.DeepEqual(t, scenario.Defenders, []simulator.Defender{
+		{Name: "Knight", City: "Bar", Strength: 2, Strategy: simulator.HuntStrategy{}},
+		{Name: "Guard", City: "Foo"},
+	})
+	assert.DeepEqual(t, scenario.Actions, []ScheduledAction{
+		{Step: 5, Action: simulator.SpawnAlien{Name: "Blorg", City: "Bar", Attributes: map[string]string{}}},
+		{Step: 10, Every: 20, Times: 3, Action: simulator.Wave{Name: "reinforcements", Count: 4, Cities: []string{"Foo", "Bar"}}},
+		{Step: 1, Every: 5, Action: simulator.Wave{Name: "trickle", Count: 1}},
+		{Step: 7, Action: simulator.Rebuild{City: "Foo"}},
+		{Step: 2, Action: simulator.CloseRoad{City: "Foo", Direction: "east"}},
+		{Step: 4, Action: simulator.ReopenRoad{City: "Foo", Direction: "east"}},
+	})
+	assert.DeepEqual(t, scenario.Expectations, []Expectation{
+		{Kind: "destroyed", Arguments: []string{"Foo"}},
+		{Kind: "events", Arguments: []string{"2", "city", "destroyed"}},
+	})
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse([]string{"seed 1"})
+	assert.Error(t, err, "map file is not set")
+	_, err = Parse([]string{"map a.txt", "invade now"})
+	assert.Error(t, err, "line 2: unknown keyword invade")
+	_, err = Parse([]string{"map a.txt", "steps many"})
+	assert.Error(t, err, "line 2: expected a non-negative number but got many")
+	_, err = Parse([]string{"map a.txt", "rule gravity on"})
+	assert.Error(t, err, "line 2: unknown rule gravity")
+	_, err = Parse([]string{"map a.txt", "rule headon maybe"})
+	assert.Error(t, err, "line 2: expected on or off but got maybe")
+	_, err = Parse([]string{"map a.txt", "alien Zorg Foo grey"})
+	assert.Error(t, err, "line 2: expected key=value attribute but got grey")
+	_, err = Parse([]string{"map a.txt", "at 5 dance"})
+	assert.Error(t, err, "line 2: unknown action dance")
+	_, err = Parse([]string{"map a.txt", "rule roadfailure 2"})
+	assert.Error(t, err, "line 2: expected probability between 0 and 1 but got 2")
+	_, err = Parse([]string{"map a.txt", "rule hitpoints -1"})
+	assert.Error(t, err, "line 2: expected non-negative hit points but got -1")
+	_, err = Parse([]string{"map a.txt", "rule fuel grey:lots"})
+	assert.Error(t, err, "line 2: expected a non-negative number but got lots")
+	_, err = Parse([]string{"map a.txt", "format svg"})
+	assert.Error(t, err, "line 2: expected format text or format ascii")
+	_, err = Parse([]string{"map a.txt", "at 5 close Foo"})
+	assert.Error(t, err, "line 2: expected close <city> <direction>")
+	_, err = Parse([]string{"map a.txt", "defender Knight Foo speed=2"})
+	assert.Error(t, err, "line 2: unknown defender attribute speed")
+	_, err = Parse([]string{"map a.txt", "at 5 every 0 wave w 1"})
+	assert.Error(t, err, "line 2: period should be positive")
+	_, err = Parse([]string{"map a.txt", "at 5 wave w"})
+	assert.Error(t, err, "line 2: expected wave <name> <count> [city,city...]")
+	_, err = Parse([]string{"map a.txt", "expect victory"})
+	assert.Error(t, err, "line 2: unknown expectation victory")
+	_, err = Parse([]string{"map a.txt", "expect aliens"})
+	assert.Error(t, err, "line 2: expectation aliens requires 1 argument(s)")
+}
+
+func TestCheck(t *testing.T) {
+	scenario, err := Parse([]string{
+		"map world.txt",
+		"expect destroyed Foo",
+		"expect survives Bar",
+		"expect alive Zorg",
+		"expect aliens 2",
+		"expect reason no aliens left",
+		"expect credit first 1",
+		"expect credit second 1",
+		"expect rebuilt Foo 1",
+		"expect trapped 1",
+		"expect defenders 1",
+		"expect kills Knight 3",
+		"expect reached 1",
+		"expect casualties 10",
+		"expect survivors 90",
+		"expect occupied 2",
+		"expect recovered 1",
+	})
+	assert.NilError(t, err)
+	wm := world.InitWorldMap()
+	wm.AddCity("Foo", map[string]string{"east": "Bar"})
+	wm.AddAlien(&world.Alien{Name: "Zorg", City: "Foo"})
+	failures := scenario.Check(wm, simulator.Result{
+		Reason:     simulator.StepLimitReached,
+		Events:     []simulator.Event{{Type: simulator.CityDestroyed, Aliens: []string{"X", "Y"}}},
+		AlienWaves: map[string]string{"X": "first", "Y": "first"},
+		Defenders:  []simulator.Defender{{Name: "Knight", Kills: 2, Fallen: true}},
+		Goals:      []simulator.Goal{{Alien: "Zorg", Target: "Bar"}},
+		Population: simulator.Population{Initial: 100, Lost: 10, Saved: 90},
+		Epidemic:   []simulator.SIR{{Step: 0, Susceptible: 2}, {Step: 1, Occupied: 1, Recovered: 1}},
+	})
+	assert.Assert(t, len(failures) == 10)
+	assert.Error(t, failures[0], "expected destroyed Foo")
+	assert.Error(t, failures[1], "expected aliens 2 but got 1")
+	assert.Error(t, failures[2], "expected reason no aliens left but got step limit reached")
+	assert.Error(t, failures[3], "expected credit second 1 but got 0")
+	assert.Error(t, failures[4], "expected rebuilt Foo 1 but got 0")
+	assert.Error(t, failures[5], "expected trapped 1 but got 0")
+	assert.Error(t, failures[6], "expected defenders 1 but got 0")
+	assert.Error(t, failures[7], "expected kills Knight 3 but got 2")
+	assert.Error(t, failures[8], "expected reached 1 but got 0")
+	assert.Error(t, failures[9], "expected occupied 2 but got 1")
+}
+
+func TestSampleScenarios(t *testing.T) {
+	files, err := filepath.Glob("../sample/scenarios/*.scenario")
+	assert.NilError(t, err)
+	assert.Assert(t, len(files) > 0)
+	for _, file := range files {
+		scenario, err := Load(file)
+		assert.NilError(t, err)
+		worldMap, result, err := scenario.Run()
+		assert.NilError(t, err)
+		for _, failure := range scenario.Check(worldMap, result) {
+			t.Errorf("%s: %s", file, failure)
+		}
+	}
+}
+
+func TestRunBaselineOnce(t *testing.T) {
+	scenario, err := Load("../sample/scenarios/capital_policy.scenario")
+	assert.NilError(t, err)
+	first, _, err := scenario.RunBaseline()
+	assert.NilError(t, err)
+	second, _, err := scenario.RunBaseline()
+	assert.NilError(t, err)
+	assert.Assert(t, first == second)
+}
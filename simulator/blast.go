@@ -0,0 +1,132 @@
+package simulator
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/luckychess/invasion/world"
+)
+
+// hitPointsKey is the city attribute keeping its hit points.
+const hitPointsKey = "hitpoints"
+
+// Blast describes collateral damage caused by destruction of a city. Every city within
+// the radius takes damage equal to amount of aliens in the fight divided by the distance
+// in roads. Damage reduces hit points of the city and the city collapses together with all
+// the aliens in it when they are exhausted. Collapses don't cause blasts themselves.
+type Blast struct {
+	// Radius is the maximal distance in roads affected by the blast, 0 disables blasts.
+	Radius uint32
+	// HitPoints of cities without hitpoints attribute, 0 means such cities take no damage.
+	HitPoints float64
+	// DestroyRoads makes the blast destroy all the roads between cities within the radius.
+	DestroyRoads bool
+}
+
+// blast spreads damage from the city destroyed by the given aliens.
+func (sim *simulator) blast(center string, aliens []string) {
+	if sim.rules.Blast.Radius == 0 {
+		return
+	}
+	distances := sim.blastArea(center)
+	names := make([]string, 0, len(distances))
+	for name := range distances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if sim.rules.Blast.DestroyRoads {
+		sim.blastRoads(names, distances, aliens)
+	}
+	for _, name := range names {
+		city := sim.worldMap.GetCities()[name]
+		hitPoints, err := city.GetMetadataFloat(hitPointsKey, sim.rules.Blast.HitPoints)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		if hitPoints <= 0 {
+			continue
+		}
+		hitPoints -= float64(len(aliens)) / float64(distances[name])
+		if hitPoints > 0 {
+			sim.worldMap.SetMetadata(name, hitPointsKey, strconv.FormatFloat(hitPoints, 'g', -1, 64))
+			continue
+		}
+		killed := sim.worldMap.RuinCity(name)
+		log.Printf("%s has collapsed after the blast in %s", name, center)
+		if len(killed) > 0 {
+			log.Printf("Aliens %s have been killed in the ruins of %s", strings.Join(killed, " "), name)
+		}
+		sim.record(CityCollapsed, []string{name}, killed)
+		sim.bury(name)
+		if sim.rules.RebuildAfter > 0 {
+			sim.Schedule(sim.step+sim.rules.RebuildAfter, Rebuild{City: name})
+		}
+	}
+}
+
+// blastArea returns distances in roads from the destroyed city to the cities within the blast radius.
+// The first ring is formed by former neighbours remembered by the ruin.
+func (sim *simulator) blastArea(center string) map[string]uint32 {
+	distances := make(map[string]uint32)
+	ruin := sim.worldMap.GetRuins()[center]
+	if ruin == nil {
+		return distances
+	}
+	cities := sim.worldMap.GetCities()
+	ring := make([]string, 0)
+	for _, link := range ruin.Links {
+		neighbour := link.To
+		if neighbour == center {
+			neighbour = link.From
+		}
+		if _, seen := distances[neighbour]; !seen && cities[neighbour] != nil {
+			distances[neighbour] = 1
+			ring = append(ring, neighbour)
+		}
+	}
+	for distance := uint32(2); distance <= sim.rules.Blast.Radius; distance++ {
+		next := make([]string, 0)
+		for _, name := range ring {
+			for _, road := range roadsOf(cities[name]) {
+				if _, seen := distances[road.To.Name]; !seen {
+					distances[road.To.Name] = distance
+					next = append(next, road.To.Name)
+				}
+			}
+		}
+		ring = next
+	}
+	return distances
+}
+
+// blastRoads destroys roads between the cities affected by the blast.
+func (sim *simulator) blastRoads(names []string, distances map[string]uint32, aliens []string) {
+	for _, name := range names {
+		city := sim.worldMap.GetCities()[name]
+		for _, direction := range city.GetDirections() {
+			road := city.Roads[direction]
+			if road == nil {
+				// already destroyed together with the road back
+				continue
+			}
+			if _, affected := distances[road.To.Name]; affected {
+				sim.worldMap.DestroyRoad(name, direction)
+				log.Printf("Road between %s and %s has been destroyed by the blast", name, road.To.Name)
+				sim.record(RoadDestroyed, uniqueSorted([]string{name, road.To.Name}), aliens)
+			}
+		}
+	}
+}
+
+// roadsOf returns open roads of the city in order of its directions.
+func roadsOf(city *world.City) []*world.Road {
+	directions := city.GetDirections()
+	roads := make([]*world.Road, 0, len(directions))
+	for _, direction := range directions {
+		roads = append(roads, city.Roads[direction])
+	}
+	return roads
+}
@@ -0,0 +1,72 @@
+package simulator
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/luckychess/invasion/world"
+	"gotest.tools/v3/assert"
+)
+
+func TestBlastDamagesCities(t *testing.T) {
+	wm := createLineMap()
+	wm.SetMetadata("D", "hitpoints", "10")
+	wm.AddAlien(&world.Alien{Name: "Survivor", City: "B"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(1)
+	simulator.SetRules(Rules{Blast: Blast{Radius: 2, HitPoints: 2}})
+	simulator.Schedule(1, Wave{Name: "bomb", Count: 3, Cities: []string{"C"}})
+	result := simulator.Simulate()
+	// B takes damage 3 and collapses killing the alien inside, D has enough hit points to survive,
+	// A and E take damage 1.5
+	assert.Assert(t, result.Count(CityDestroyed) == 1)
+	assert.DeepEqual(t, result.Events[len(result.Events)-1], Event{Step: 1, Time: 1, Type: CityCollapsed, Cities: []string{"B"}, Aliens: []string{"Survivor"}})
+	assert.Assert(t, wm.GetCities()["B"] == nil)
+	assert.Assert(t, wm.GetCities()["D"].Metadata["hitpoints"] == "7")
+	assert.Assert(t, wm.GetCities()["A"].Metadata["hitpoints"] == "0.5")
+	assert.Assert(t, wm.GetCities()["E"].Metadata["hitpoints"] == "0.5")
+	assert.Assert(t, wm.GetCities()["D"].Roads["east"] != nil)
+}
+
+func TestBlastDestroysRoads(t *testing.T) {
+	wm := createLineMap()
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(1)
+	simulator.SetRules(Rules{Blast: Blast{Radius: 2, DestroyRoads: true}})
+	simulator.Schedule(1, Wave{Name: "bomb", Count: 2, Cities: []string{"C"}})
+	result := simulator.Simulate()
+	// cities without hit points take no damage but roads within the radius are destroyed
+	assert.Assert(t, len(wm.GetCities()) == 4)
+	assert.Assert(t, result.Count(CityCollapsed) == 0)
+	assert.Assert(t, result.Count(RoadDestroyed) == 2)
+	assert.DeepEqual(t, result.DestructionByWave(), map[string]int{"bomb": 3})
+	for _, city := range wm.GetCities() {
+		assert.Assert(t, len(city.Roads) == 0)
+	}
+}
+
+func TestNoBlastByDefault(t *testing.T) {
+	wm := createLineMap()
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(1)
+	simulator.Schedule(1, Wave{Name: "bomb", Count: 2, Cities: []string{"C"}})
+	simulator.Simulate()
+	assert.Assert(t, wm.GetCities()["B"].Roads["west"] != nil)
+	assert.Assert(t, len(wm.GetCities()["B"].Metadata) == 0)
+}
+
+func TestBlastCollapsesCityLaterInFightCheck(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddCity("B", map[string]string{"east": "C"})
+	wm.SetMetadata("B", "hitpoints", "1")
+	wm.AddAlien(&world.Alien{Name: "X", City: "A"})
+	wm.AddAlien(&world.Alien{Name: "Y", City: "A"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetRules(Rules{Blast: Blast{Radius: 1}})
+	// B collapses after A is destroyed and is skipped by the rest of the fight check
+	result := simulator.Simulate()
+	assert.Assert(t, result.Reason == NoAliensLeft)
+	assert.Assert(t, result.Count(CityDestroyed) == 1 && result.Count(CityCollapsed) == 1)
+	assert.DeepEqual(t, wm.GetCities()["C"].Roads, map[string]*world.Road{})
+}
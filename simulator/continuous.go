@@ -0,0 +1,174 @@
+package simulator
+
+import (
+	"container/heap"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Engine defines how simulation time advances.
+type Engine int
+
+const (
+	// DiscreteEngine moves aliens in steps according to Rules.Movement.
+	DiscreteEngine Engine = iota
+	// ContinuousEngine moves every alien after a random delay given by Rules.Delay.
+	// Moves are processed one by one in the order of their exact times.
+	ContinuousEngine
+)
+
+// Delay produces time intervals between two consecutive moves of an alien.
+type Delay interface {
+	Next(rng *rand.Rand) float64
+}
+
+// ExponentialDelay makes aliens move as a Poisson process with given rate of moves per time unit.
+type ExponentialDelay struct {
+	Rate float64
+}
+
+// Next returns exponentially distributed delay.
+func (d ExponentialDelay) Next(rng *rand.Rand) float64 {
+	return rng.ExpFloat64() / d.Rate
+}
+
+// UniformDelay makes aliens wait uniformly distributed time between Min and Max.
+type UniformDelay struct {
+	Min float64
+	Max float64
+}
+
+// Next returns uniformly distributed delay.
+func (d UniformDelay) Next(rng *rand.Rand) float64 {
+	return d.Min + rng.Float64()*(d.Max-d.Min)
+}
+
+// FixedDelay makes aliens move periodically.
+type FixedDelay struct {
+	Value float64
+}
+
+// Next returns the fixed delay.
+func (d FixedDelay) Next(rng *rand.Rand) float64 {
+	return d.Value
+}
+
+// ParseDelay creates a delay from its description: exp:<rate>, uniform:<min>:<max> or fixed:<value>.
+func ParseDelay(spec string) (Delay, error) {
+	parts := strings.Split(spec, ":")
+	values := make([]float64, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("wrong delay parameter %s", part)
+		}
+		values = append(values, value)
+	}
+	switch {
+	case parts[0] == "exp" && len(values) == 1 && values[0] > 0:
+		return ExponentialDelay{Rate: values[0]}, nil
+	case parts[0] == "uniform" && len(values) == 2 && values[0] <= values[1] && values[1] > 0:
+		return UniformDelay{Min: values[0], Max: values[1]}, nil
+	case parts[0] == "fixed" && len(values) == 1 && values[0] > 0:
+		return FixedDelay{Value: values[0]}, nil
+	}
+	return nil, fmt.Errorf("wrong delay %s, expected exp:<rate>, uniform:<min>:<max> or fixed:<value>", spec)
+}
+
+// moveEvent is a scheduled move of an alien.
+type moveEvent struct {
+	time  float64
+	alien string
+}
+
+// moveQueue is a priority queue of moves ordered by time, ties are broken by alien names.
+type moveQueue []moveEvent
+
+func (q moveQueue) Len() int { return len(q) }
+func (q moveQueue) Less(i, j int) bool {
+	return q[i].time < q[j].time || q[i].time == q[j].time && q[i].alien < q[j].alien
+}
+func (q moveQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *moveQueue) Push(x interface{}) { *q = append(*q, x.(moveEvent)) }
+func (q *moveQueue) Pop() interface{} {
+	old := *q
+	event := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return event
+}
+
+// simulateContinuous runs the event-driven engine until all the aliens are dead or simulation
+// time exceeds the steps count. Every alien moves after a random delay and fights are checked
+// immediately at the exact arrival time. Actions scheduled for a step happen at its beginning,
+// e.g. actions of step 1 happen at time 0.
+func (sim *simulator) simulateContinuous() Result {
+	delay := sim.rules.Delay
+	if delay == nil {
+		delay = ExponentialDelay{Rate: 1}
+	}
+	queue := &moveQueue{}
+	queued := make(map[string]bool)
+	enqueueNewAliens := func() {
+		for _, name := range sim.alienNames() {
+			if !queued[name] {
+				queued[name] = true
+				heap.Push(queue, moveEvent{time: sim.time + delay.Next(sim.rng), alien: name})
+			}
+		}
+	}
+	enqueueNewAliens()
+	// roads fail and defenders move at the beginning of every step right after its scheduled actions
+	begun := uint32(0)
+	beginStepsUntil := func(step uint32) {
+		for ; begun < step; begun++ {
+			sim.observe(begun)
+			sim.step, sim.time = begun+1, float64(begun)
+			sim.beginStep()
+			// children are born at the beginning of the step
+			enqueueNewAliens()
+		}
+	}
+	applied := uint32(0)
+	reason := StepLimitReached
+	for {
+		actionStep, pending := sim.nextActionStep(applied)
+		if len(sim.worldMap.GetAliens()) == 0 && !pending {
+			log.Println("No more aliens to fight, stopping simulation")
+			reason = NoAliensLeft
+			break
+		}
+		if pending && (queue.Len() == 0 || float64(actionStep-1) <= (*queue)[0].time) {
+			beginStepsUntil(actionStep - 1)
+			sim.time = float64(actionStep - 1)
+			sim.step = actionStep
+			sim.applyScheduled(actionStep)
+			beginStepsUntil(actionStep)
+			applied = actionStep
+			enqueueNewAliens()
+			continue
+		}
+		if queue.Len() == 0 || (*queue)[0].time > float64(sim.stepsCount) {
+			sim.time = float64(sim.stepsCount)
+			sim.step = sim.stepsCount
+			break
+		}
+		move := heap.Pop(queue).(moveEvent)
+		alien := sim.worldMap.GetAliens()[move.alien]
+		if alien == nil {
+			delete(queued, move.alien)
+			continue
+		}
+		beginStepsUntil(uint32(math.Ceil(move.time)))
+		sim.time = move.time
+		sim.step = uint32(math.Ceil(move.time))
+		sim.moveAndFight(alien)
+		heap.Push(queue, moveEvent{time: move.time + delay.Next(sim.rng), alien: move.alien})
+		// inhabitants converted by the move start moving as well
+		enqueueNewAliens()
+	}
+	return sim.result(reason)
+}
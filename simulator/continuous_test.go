@@ -0,0 +1,98 @@
+package simulator
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/luckychess/invasion/world"
+	"gotest.tools/v3/assert"
+)
+
+func TestParseDelay(t *testing.T) {
+	delay, err := ParseDelay("exp:2")
+	assert.NilError(t, err)
+	assert.Equal(t, delay, Delay(ExponentialDelay{Rate: 2}))
+	delay, err = ParseDelay("uniform:0.5:1.5")
+	assert.NilError(t, err)
+	assert.Equal(t, delay, Delay(UniformDelay{Min: 0.5, Max: 1.5}))
+	delay, err = ParseDelay("fixed:1")
+	assert.NilError(t, err)
+	assert.Equal(t, delay, Delay(FixedDelay{Value: 1}))
+	_, err = ParseDelay("exp:0")
+	assert.ErrorContains(t, err, "wrong delay exp:0")
+	_, err = ParseDelay("uniform:2:1")
+	assert.ErrorContains(t, err, "wrong delay uniform:2:1")
+	_, err = ParseDelay("fixed:soon")
+	assert.Error(t, err, "wrong delay parameter soon")
+}
+
+func TestDelays(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	total := 0.0
+	for i := 0; i < 10000; i++ {
+		total += ExponentialDelay{Rate: 4}.Next(rng)
+		uniform := UniformDelay{Min: 1, Max: 2}.Next(rng)
+		assert.Assert(t, uniform >= 1 && uniform < 2)
+	}
+	// mean of exponential distribution is 1/rate
+	assert.Assert(t, math.Abs(total/10000-0.25) < 0.01)
+	assert.Assert(t, FixedDelay{Value: 3}.Next(rng) == 3)
+}
+
+func TestContinuousFixedDelay(t *testing.T) {
+	wm := createSwapMap()
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetRules(Rules{Engine: ContinuousEngine, Delay: FixedDelay{Value: 1}})
+	result := simulator.Simulate()
+	// both aliens are going to move at time 1, X moves first and finds Y in B
+	assert.Assert(t, result.Reason == NoAliensLeft)
+	assert.DeepEqual(t, result.Events, []Event{{Step: 1, Time: 1, Type: CityDestroyed, Cities: []string{"B"}, Aliens: []string{"X", "Y"}}})
+}
+
+func TestContinuousExponentialDelay(t *testing.T) {
+	wm := createSwapMap()
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetRules(Rules{Engine: ContinuousEngine})
+	result := simulator.Simulate()
+	// whoever moves first finds another alien at the exact time of the move
+	assert.Assert(t, result.Reason == NoAliensLeft)
+	assert.Assert(t, len(result.Events) == 1)
+	event := result.Events[0]
+	assert.Assert(t, event.Time > 0 && event.Time != math.Floor(event.Time))
+	assert.Assert(t, event.Step == uint32(math.Ceil(event.Time)))
+	assert.Assert(t, result.Time == event.Time)
+}
+
+func TestContinuousStepLimit(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddAlien(&world.Alien{Name: "Loner", City: "A"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetRules(Rules{Engine: ContinuousEngine, Delay: UniformDelay{Min: 0.5, Max: 1.5}})
+	result := simulator.Simulate()
+	assert.Assert(t, result.Reason == StepLimitReached)
+	assert.Assert(t, result.Steps == simulatorSteps)
+	assert.Assert(t, result.Time == simulatorSteps)
+	assert.Assert(t, len(wm.GetAliens()) == 1)
+}
+
+func TestContinuousNewbornMoves(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddRoad("A", "east", "B", world.RoadOptions{OneWay: true})
+	wm.AddRoad("B", "south", "C", world.RoadOptions{OneWay: true})
+	wm.AddRoad("C", "west", "D", world.RoadOptions{OneWay: true})
+	wm.AddRoad("D", "north", "A", world.RoadOptions{OneWay: true})
+	wm.AddAlien(&world.Alien{Name: "X", City: "A"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(12)
+	simulator.SetRules(Rules{Engine: ContinuousEngine, Delay: FixedDelay{Value: 2}, Reproduction: Reproduction{Every: 5, Cap: 2}})
+	result := simulator.Simulate()
+	// the child is born in C at time 4 next to its parent and follows it into D
+	assert.Assert(t, result.Count(AlienBorn) == 1)
+	assert.DeepEqual(t, result.Events[0].Cities, []string{"C"})
+	event := result.Events[len(result.Events)-1]
+	assert.Assert(t, event.Type == CityDestroyed && event.Time == 6)
+	assert.DeepEqual(t, event.Cities, []string{"D"})
+	assert.Assert(t, len(event.Aliens) == 2)
+}
@@ -0,0 +1,226 @@
+package simulator
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/luckychess/invasion/world"
+)
+
+// Defender is a unit protecting the world from aliens. Defenders move along open roads
+// at the beginning of every step ignoring road length and engage aliens in their city
+// before the aliens fight each other. Defenders win if their total strength is not less
+// than amount of aliens in the city, otherwise all of them fall.
+type Defender struct {
+	Name string
+	City string
+	// Strength is amount of aliens the defender is able to fight at once, 0 means 1.
+	Strength uint32
+	// Strategy chooses where the defender moves, defenders guard their city by default.
+	Strategy DefenderStrategy
+	// Kills is amount of aliens killed by the defender together with its allies.
+	Kills uint32
+	// Fallen defenders have been killed by aliens or have fallen together with their city.
+	Fallen bool
+}
+
+// DefenderStrategy chooses the next city of a defender.
+type DefenderStrategy interface {
+	// Next returns name of the city the defender moves to, which is either
+	// the current city or one of its neighbours by open roads.
+	Next(city *world.City, rng *rand.Rand) string
+}
+
+// GuardStrategy keeps the defender in its city.
+type GuardStrategy struct{}
+
+func (GuardStrategy) Next(city *world.City, rng *rand.Rand) string {
+	return city.Name
+}
+
+// PatrolStrategy moves the defender along a random open road.
+type PatrolStrategy struct{}
+
+func (PatrolStrategy) Next(city *world.City, rng *rand.Rand) string {
+	directions := city.GetDirections()
+	if len(directions) == 0 {
+		return city.Name
+	}
+	return city.Roads[directions[rng.Intn(len(directions))]].To.Name
+}
+
+// HuntStrategy keeps the defender in a city with aliens, otherwise moves it to the neighbour
+// with most aliens or patrols if there are no aliens around.
+type HuntStrategy struct{}
+
+func (HuntStrategy) Next(city *world.City, rng *rand.Rand) string {
+	if len(city.Aliens) > 0 {
+		return city.Name
+	}
+	target, most := "", 0
+	for _, direction := range city.GetDirections() {
+		if neighbour := city.Roads[direction].To; len(neighbour.Aliens) > most {
+			target, most = neighbour.Name, len(neighbour.Aliens)
+		}
+	}
+	if target == "" {
+		return PatrolStrategy{}.Next(city, rng)
+	}
+	return target
+}
+
+// ParseDefenderStrategy converts strategy name (guard, patrol or hunt) into a strategy.
+func ParseDefenderStrategy(name string) (DefenderStrategy, error) {
+	switch name {
+	case "guard":
+		return GuardStrategy{}, nil
+	case "patrol":
+		return PatrolStrategy{}, nil
+	case "hunt":
+		return HuntStrategy{}, nil
+	}
+	return nil, fmt.Errorf("unknown defender strategy %s", name)
+}
+
+// AddDefender places a defender into the world before the simulation starts.
+func (sim *simulator) AddDefender(defender Defender) error {
+	if sim.worldMap.GetCities()[defender.City] == nil {
+		return fmt.Errorf("trying to place a defender %s into non-existing city %s", defender.Name, defender.City)
+	}
+	for _, other := range sim.defenders {
+		if other.Name == defender.Name {
+			return fmt.Errorf("defender %s already exists", defender.Name)
+		}
+	}
+	if defender.Strength == 0 {
+		defender.Strength = 1
+	}
+	if defender.Strategy == nil {
+		defender.Strategy = GuardStrategy{}
+	}
+	sim.defenders = append(sim.defenders, &defender)
+	sim.posts[defender.Name] = defender.City
+	sort.Slice(sim.defenders, func(i, j int) bool { return sim.defenders[i].Name < sim.defenders[j].Name })
+	return nil
+}
+
+// resetDefenders returns every defender to its starting city without kills, so every simulation
+// starts with the same defence. Defenders of cities destroyed since then start fallen.
+func (sim *simulator) resetDefenders() {
+	for _, defender := range sim.defenders {
+		defender.City = sim.posts[defender.Name]
+		defender.Kills = 0
+		defender.Fallen = sim.worldMap.GetCities()[defender.City] == nil
+	}
+}
+
+// fallWith marks defenders in the destroyed city as fallen.
+func (sim *simulator) fallWith(city string) {
+	for _, defender := range sim.defenders {
+		if !defender.Fallen && defender.City == city {
+			defender.Fallen = true
+			log.Printf("Defender %s has fallen together with %s", defender.Name, city)
+		}
+	}
+}
+
+// moveDefenders moves every defender alive according to its strategy and engages aliens
+// in the cities defenders arrive to.
+func (sim *simulator) moveDefenders() {
+	if len(sim.defenders) == 0 {
+		return
+	}
+	cities := sim.worldMap.GetCities()
+	for _, defender := range sim.defenders {
+		if defender.Fallen {
+			continue
+		}
+		defender.City = defender.Strategy.Next(cities[defender.City], sim.rng)
+	}
+	for _, city := range sim.defendedCities() {
+		sim.engage(city)
+	}
+}
+
+// defendedCities returns sorted names of the cities with defenders alive.
+func (sim *simulator) defendedCities() []string {
+	cities := make([]string, 0, len(sim.defenders))
+	for _, defender := range sim.defenders {
+		if !defender.Fallen {
+			cities = append(cities, defender.City)
+		}
+	}
+	return uniqueSorted(cities)
+}
+
+// isDefended checks whether there are defenders alive in the city.
+func (sim *simulator) isDefended(city string) bool {
+	for _, defender := range sim.defenders {
+		if !defender.Fallen && defender.City == city {
+			return true
+		}
+	}
+	return false
+}
+
+// engage makes defenders in the city fight aliens there.
+func (sim *simulator) engage(cityName string) {
+	defenders := make([]*Defender, 0)
+	names := make([]string, 0)
+	strength := uint32(0)
+	for _, defender := range sim.defenders {
+		if !defender.Fallen && defender.City == cityName {
+			defenders = append(defenders, defender)
+			names = append(names, defender.Name)
+			strength += defender.Strength
+		}
+	}
+	if len(defenders) == 0 {
+		return
+	}
+	city := sim.worldMap.GetCities()[cityName]
+	if city == nil || len(city.Aliens) == 0 {
+		return
+	}
+	aliens := make([]string, 0, len(city.Aliens))
+	for alien := range city.Aliens {
+		aliens = append(aliens, alien)
+	}
+	sort.Strings(aliens)
+	if strength >= uint32(len(aliens)) {
+		for _, alien := range aliens {
+			sim.worldMap.RemoveAlien(alien)
+		}
+		for _, defender := range defenders {
+			defender.Kills += uint32(len(aliens))
+		}
+		log.Printf("Defenders %s have killed aliens %s in %s", strings.Join(names, " "), strings.Join(aliens, " "), cityName)
+		sim.recordDefence(AliensKilled, cityName, aliens, names)
+		return
+	}
+	for _, defender := range defenders {
+		defender.Fallen = true
+	}
+	log.Printf("Defenders %s have been killed by aliens %s in %s", strings.Join(names, " "), strings.Join(aliens, " "), cityName)
+	sim.recordDefence(DefendersKilled, cityName, aliens, names)
+}
+
+func (sim *simulator) recordDefence(eventType EventType, city string, aliens []string, defenders []string) {
+	sim.record(eventType, []string{city}, aliens)
+	sim.events[len(sim.events)-1].Defenders = defenders
+}
+
+// defendersReport returns copies of all the defenders sorted by name.
+func (sim *simulator) defendersReport() []Defender {
+	if len(sim.defenders) == 0 {
+		return nil
+	}
+	report := make([]Defender, 0, len(sim.defenders))
+	for _, defender := range sim.defenders {
+		report = append(report, *defender)
+	}
+	return report
+}
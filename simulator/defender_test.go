@@ -0,0 +1,124 @@
+package simulator
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/luckychess/invasion/world"
+	"gotest.tools/v3/assert"
+)
+
+func TestDefenderKillsAliens(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("Fort", map[string]string{})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	assert.NilError(t, simulator.AddDefender(Defender{Name: "Knight", City: "Fort", Strength: 2}))
+	simulator.Schedule(2, SpawnAlien{Name: "X", City: "Fort"})
+	simulator.Schedule(2, Wave{Name: "big", Count: 3, Cities: []string{"Fort"}})
+	result := simulator.Simulate()
+	// the first alien is killed on arrival, the wave of 3 is too strong for the defender
+	// and then destroys the city
+	assert.Assert(t, len(result.Events) == 4)
+	assert.DeepEqual(t, result.Events[0], Event{Step: 2, Time: 2, Type: AliensKilled, Cities: []string{"Fort"}, Aliens: []string{"X"}, Defenders: []string{"Knight"}})
+	assert.Assert(t, result.Events[1].Type == WaveArrived)
+	assert.Assert(t, result.Events[2].Type == DefendersKilled)
+	assert.Assert(t, result.Events[3].Type == CityDestroyed)
+	assert.Assert(t, len(result.Defenders) == 1)
+	assert.Assert(t, result.Defenders[0].Fallen)
+	assert.Assert(t, result.Defenders[0].Kills == 1)
+}
+
+func TestDefenderSavesCity(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("Fort", map[string]string{})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.AddDefender(Defender{Name: "A", City: "Fort"})
+	simulator.AddDefender(Defender{Name: "B", City: "Fort"})
+	simulator.Schedule(1, Wave{Name: "pair", Count: 2, Cities: []string{"Fort"}})
+	result := simulator.Simulate()
+	// defenders together are strong enough to kill both aliens before they destroy the city
+	assert.Assert(t, result.Count(AliensKilled) == 1)
+	assert.Assert(t, result.Count(CityDestroyed) == 0)
+	assert.Assert(t, wm.GetCities()["Fort"] != nil)
+	assert.Assert(t, result.Defenders[0].Kills == 2 && result.Defenders[1].Kills == 2)
+}
+
+func TestAddDefenderErrors(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("Fort", map[string]string{})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	assert.Error(t, simulator.AddDefender(Defender{Name: "A", City: "Atlantis"}), "trying to place a defender A into non-existing city Atlantis")
+	assert.NilError(t, simulator.AddDefender(Defender{Name: "A", City: "Fort"}))
+	assert.Error(t, simulator.AddDefender(Defender{Name: "A", City: "Fort"}), "defender A already exists")
+}
+
+func TestDefenderStrategies(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B", "west": "C"})
+	wm.AddAlien(&world.Alien{Name: "X", City: "C"})
+	a := wm.GetCities()["A"]
+	rng := rand.New(rand.NewSource(0))
+	assert.Assert(t, GuardStrategy{}.Next(a, rng) == "A")
+	assert.Assert(t, HuntStrategy{}.Next(a, rng) == "C")
+	assert.Assert(t, HuntStrategy{}.Next(wm.GetCities()["C"], rng) == "C")
+	next := PatrolStrategy{}.Next(a, rng)
+	assert.Assert(t, next == "B" || next == "C")
+	wm.CloseRoad("A", "east")
+	wm.CloseRoad("A", "west")
+	assert.Assert(t, PatrolStrategy{}.Next(a, rng) == "A")
+
+	for name, expected := range map[string]DefenderStrategy{"guard": GuardStrategy{}, "patrol": PatrolStrategy{}, "hunt": HuntStrategy{}} {
+		strategy, err := ParseDefenderStrategy(name)
+		assert.NilError(t, err)
+		assert.Equal(t, strategy, expected)
+	}
+	_, err := ParseDefenderStrategy("flee")
+	assert.Error(t, err, "unknown defender strategy flee")
+}
+
+func TestHunterCatchesAlien(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddAlien(&world.Alien{Name: "X", City: "B"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(10)
+	simulator.AddDefender(Defender{Name: "Hunter", City: "A", Strategy: HuntStrategy{}})
+	result := simulator.Simulate()
+	// the hunter moves into B at the beginning of the first step before the alien leaves
+	assert.Assert(t, result.Reason == NoAliensLeft)
+	assert.DeepEqual(t, result.Events, []Event{{Step: 1, Time: 1, Type: AliensKilled, Cities: []string{"B"}, Aliens: []string{"X"}, Defenders: []string{"Hunter"}}})
+	assert.Assert(t, result.Defenders[0].City == "B")
+}
+
+func TestDefenderFallsWithCollapsedCity(t *testing.T) {
+	wm := createLineMap()
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(1)
+	simulator.SetRules(Rules{Blast: Blast{Radius: 1, HitPoints: 1}})
+	simulator.AddDefender(Defender{Name: "Guard", City: "B"})
+	simulator.Schedule(1, Wave{Name: "bomb", Count: 2, Cities: []string{"C"}})
+	result := simulator.Simulate()
+	// the guard never meets aliens but B collapses after the blast in C
+	assert.Assert(t, result.Count(CityCollapsed) == 2)
+	assert.Assert(t, result.Count(DefendersKilled) == 0)
+	assert.Assert(t, result.Defenders[0].Fallen)
+
+	// the guard stays fallen in the next simulation as its city is a ruin
+	result = simulator.Simulate()
+	assert.Assert(t, result.Defenders[0].Fallen)
+}
+
+func TestDefendersResetBetweenSimulations(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddAlien(&world.Alien{Name: "X", City: "B"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(10)
+	simulator.AddDefender(Defender{Name: "Hunter", City: "A", Strategy: HuntStrategy{}})
+	result := simulator.Simulate()
+	assert.Assert(t, result.Defenders[0].City == "B" && result.Defenders[0].Kills == 1)
+	// there are no aliens left, so the hunter stays in its starting city without kills
+	result = simulator.Simulate()
+	assert.Assert(t, result.Defenders[0].City == "A" && result.Defenders[0].Kills == 0)
+	assert.Assert(t, !result.Defenders[0].Fallen)
+}
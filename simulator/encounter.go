@@ -0,0 +1,166 @@
+package simulator
+
+import (
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/luckychess/invasion/world"
+)
+
+// position of an alien is either a city or a place on the road.
+type position struct {
+	city    string
+	transit world.Transit
+}
+
+// traversal is a part of the road covered by an alien during a step. Road is identified
+// by its ends in alphabetical order, positions are measured from the first end
+// of the road (0) to the second one (1). Aliens staying on the road have
+// an empty traversal but still have a direction.
+type traversal struct {
+	alien    string
+	road     [2]string
+	from, to float64
+	forward  bool
+	moved    bool
+}
+
+func positionOf(alien *world.Alien) position {
+	if alien.Transit != nil {
+		return position{transit: *alien.Transit}
+	}
+	return position{city: alien.City}
+}
+
+func (sim *simulator) positions() map[string]position {
+	positions := make(map[string]position)
+	for name, alien := range sim.worldMap.GetAliens() {
+		positions[name] = positionOf(alien)
+	}
+	return positions
+}
+
+// fightOnRoads finds aliens which have traversed the same road in opposite directions
+// and met each other. Aliens which have moved are given with their previous positions,
+// other aliens on the roads are considered standing still. Aliens which have teleported
+// haven't used any road. Aliens which have met fight and destroy the road.
+func (sim *simulator) fightOnRoads(before map[string]position) {
+	traversals := make(map[[2]string][]traversal)
+	teleported := sim.teleported
+	sim.teleported = make(map[string]bool)
+	for name, alien := range sim.worldMap.GetAliens() {
+		current := positionOf(alien)
+		previous, moved := before[name]
+		if teleported[name] {
+			continue
+		}
+		if !moved {
+			if alien.Transit == nil {
+				continue
+			}
+			previous = current
+		}
+		if t, onRoad := getTraversal(name, previous, current); onRoad {
+			traversals[t.road] = append(traversals[t.road], t)
+		}
+	}
+	roads := make([][2]string, 0, len(traversals))
+	for road := range traversals {
+		roads = append(roads, road)
+	}
+	sort.Slice(roads, func(i, j int) bool {
+		return roads[i][0] < roads[j][0] || roads[i][0] == roads[j][0] && roads[i][1] < roads[j][1]
+	})
+	for _, road := range roads {
+		fighters := make(map[string]bool)
+		for _, forward := range traversals[road] {
+			for _, backward := range traversals[road] {
+				if forward.forward && !backward.forward && (forward.moved || backward.moved) &&
+					forward.from <= backward.from && forward.to >= backward.to {
+					fighters[forward.alien] = true
+					fighters[backward.alien] = true
+				}
+			}
+		}
+		if len(fighters) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(fighters))
+		for name := range fighters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sim.worldMap.RemoveAlien(name)
+		}
+		sim.destroyRoadsBetween(road[0], road[1])
+		log.Printf("Road between %s and %s has been destroyed by aliens %s", road[0], road[1], strings.Join(names, " "))
+		sim.record(RoadDestroyed, []string{road[0], road[1]}, names)
+	}
+}
+
+// destroyRoadsBetween destroys all the roads connecting two cities in both directions.
+func (sim *simulator) destroyRoadsBetween(first string, second string) {
+	cities := sim.worldMap.GetCities()
+	for _, ends := range [][2]string{{first, second}, {second, first}} {
+		city := cities[ends[0]]
+		if city == nil {
+			continue
+		}
+		for _, direction := range city.GetDirections() {
+			if city.Roads[direction].To.Name == ends[1] {
+				sim.worldMap.DestroyRoad(ends[0], direction)
+			}
+		}
+	}
+}
+
+// getTraversal finds the road and the part of it covered by an alien moving
+// from one position to another. It returns false if the alien hasn't been on a road.
+func getTraversal(alien string, previous position, current position) (traversal, bool) {
+	var road [2]string
+	switch {
+	case previous.city != "" && current.city != "":
+		if previous.city == current.city {
+			return traversal{}, false
+		}
+		road = roadEnds(previous.city, current.city)
+	case current.city == "":
+		road = roadEnds(current.transit.From, current.transit.To)
+	default:
+		road = roadEnds(previous.transit.From, previous.transit.To)
+	}
+	from, to := onRoad(road, previous), onRoad(road, current)
+	t := traversal{alien: alien, road: road, from: from, to: to, forward: to > from, moved: from != to}
+	if !t.moved {
+		if current.city != "" {
+			return traversal{}, false
+		}
+		// standing alien is heading towards the end of the road it travels to
+		t.forward = current.transit.To == road[1]
+	}
+	return t, true
+}
+
+func roadEnds(first string, second string) [2]string {
+	if first > second {
+		return [2]string{second, first}
+	}
+	return [2]string{first, second}
+}
+
+// onRoad converts a position into a distance from the first end of the road.
+func onRoad(road [2]string, p position) float64 {
+	if p.city != "" {
+		if p.city == road[0] {
+			return 0
+		}
+		return 1
+	}
+	progress := float64(p.transit.Progress()) / float64(p.transit.Length)
+	if p.transit.From == road[0] {
+		return progress
+	}
+	return 1 - progress
+}
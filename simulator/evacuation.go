@@ -0,0 +1,161 @@
+package simulator
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/luckychess/invasion/world"
+)
+
+// populationKey is the city metadata keeping amount of people living in the city.
+const populationKey = "population"
+
+// Evacuation makes people flee from cities threatened by aliens. People live in cities according
+// to their population attribute and die when their city is destroyed or collapses.
+type Evacuation struct {
+	// Capacity is amount of people able to leave a city along a single road during a step,
+	// 0 disables the population layer.
+	Capacity uint32
+	// Radius is amount of roads from an alien within which cities are threatened,
+	// 0 means only cities with aliens in them.
+	Radius uint32
+}
+
+// Population reports fate of people living in the world.
+type Population struct {
+	// Initial is amount of people at the beginning of the simulation.
+	Initial uint64
+	// Lost is amount of people killed in destroyed and collapsed cities.
+	Lost uint64
+	// Saved is amount of people alive at the end of the simulation.
+	Saved uint64
+	// Evacuated is amount of people who have fled from threatened cities, people fleeing
+	// several times are counted every time.
+	Evacuated uint64
+}
+
+// populationOf returns amount of people living in the city.
+func populationOf(city string, metadata map[string]string) uint64 {
+	value, ok := metadata[populationKey]
+	if !ok {
+		return 0
+	}
+	people, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		log.Printf("city %s has non-numeric %s: %s", city, populationKey, value)
+		return 0
+	}
+	return people
+}
+
+// countPopulation remembers initial amount of people before the simulation starts.
+func (sim *simulator) countPopulation() {
+	sim.population = Population{}
+	if sim.rules.Evacuation.Capacity == 0 {
+		return
+	}
+	for _, city := range sim.worldMap.GetCities() {
+		sim.population.Initial += populationOf(city.Name, city.Metadata)
+	}
+}
+
+// evacuate moves people from threatened cities to their neighbours which aren't threatened.
+// People leave along every such road up to its capacity and are split evenly between the roads.
+func (sim *simulator) evacuate() {
+	if sim.rules.Evacuation.Capacity == 0 {
+		return
+	}
+	threatened := sim.threatenedCities()
+	cities := sim.worldMap.GetCities()
+	for _, name := range sortedCities(cities) {
+		city := cities[name]
+		people := populationOf(city.Name, city.Metadata)
+		if !threatened[name] || people == 0 {
+			continue
+		}
+		safe := make([]*world.City, 0)
+		for _, direction := range city.GetDirections() {
+			if neighbour := city.Roads[direction].To; !threatened[neighbour.Name] {
+				safe = append(safe, neighbour)
+			}
+		}
+		for i, neighbour := range safe {
+			roads := uint64(len(safe) - i)
+			fleeing := (people + roads - 1) / roads
+			if capacity := uint64(sim.rules.Evacuation.Capacity); fleeing > capacity {
+				fleeing = capacity
+			}
+			people -= fleeing
+			sim.population.Evacuated += fleeing
+			sim.worldMap.SetMetadata(neighbour.Name, populationKey, strconv.FormatUint(populationOf(neighbour.Name, neighbour.Metadata)+fleeing, 10))
+			log.Printf("%d people have fled from %s to %s", fleeing, name, neighbour.Name)
+		}
+		if len(safe) > 0 {
+			sim.worldMap.SetMetadata(name, populationKey, strconv.FormatUint(people, 10))
+		}
+	}
+}
+
+// threatenedCities returns cities which aliens can reach within the evacuation radius.
+// Aliens travelling along a road threaten the city they are going to.
+func (sim *simulator) threatenedCities() map[string]bool {
+	cities := sim.worldMap.GetCities()
+	distances := make(map[string]uint32)
+	queue := make([]*world.City, 0)
+	for _, alien := range sim.worldMap.GetAliens() {
+		name := alien.City
+		if alien.Transit != nil {
+			name = alien.Transit.To
+		}
+		if _, seen := distances[name]; !seen && cities[name] != nil {
+			distances[name] = 0
+			queue = append(queue, cities[name])
+		}
+	}
+	for len(queue) > 0 {
+		city := queue[0]
+		queue = queue[1:]
+		if distances[city.Name] == sim.rules.Evacuation.Radius {
+			continue
+		}
+		for _, direction := range city.GetDirections() {
+			neighbour := city.Roads[direction].To
+			if _, seen := distances[neighbour.Name]; !seen {
+				distances[neighbour.Name] = distances[city.Name] + 1
+				queue = append(queue, neighbour)
+			}
+		}
+	}
+	threatened := make(map[string]bool, len(distances))
+	for name := range distances {
+		threatened[name] = true
+	}
+	return threatened
+}
+
+// bury counts people of the destroyed city as lost, so they don't come back when the city is rebuilt.
+// Defenders of the city fall together with it.
+func (sim *simulator) bury(city string) {
+	sim.fallWith(city)
+	if sim.rules.Evacuation.Capacity == 0 {
+		return
+	}
+	ruin := sim.worldMap.GetRuins()[city]
+	if ruin == nil {
+		return
+	}
+	if people := populationOf(city, ruin.Metadata); people > 0 {
+		sim.population.Lost += people
+		ruin.Metadata[populationKey] = "0"
+		log.Printf("%d people have died in %s", people, city)
+	}
+}
+
+// populationReport returns fate of people, all the people not lost are saved.
+func (sim *simulator) populationReport() Population {
+	population := sim.population
+	if population.Initial > population.Lost {
+		population.Saved = population.Initial - population.Lost
+	}
+	return population
+}
@@ -0,0 +1,42 @@
+package simulator
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/luckychess/invasion/world"
+	"gotest.tools/v3/assert"
+)
+
+func TestEvacuate(t *testing.T) {
+	wm := createLineMap()
+	wm.SetMetadata("B", "population", "30")
+	wm.SetMetadata("C", "population", "50")
+	wm.SetMetadata("D", "population", "5")
+	wm.AddAlien(&world.Alien{Name: "X", City: "C"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetRules(Rules{Evacuation: Evacuation{Capacity: 20, Radius: 1}})
+	simulator.evacuate()
+	// people of C are surrounded by threatened cities and can't flee
+	cities := wm.GetCities()
+	assert.Assert(t, cities["A"].Metadata["population"] == "20")
+	assert.Assert(t, cities["B"].Metadata["population"] == "10")
+	assert.Assert(t, cities["C"].Metadata["population"] == "50")
+	assert.Assert(t, cities["D"].Metadata["population"] == "0")
+	assert.Assert(t, cities["E"].Metadata["population"] == "5")
+	assert.Assert(t, simulator.population.Evacuated == 25)
+}
+
+func TestPopulationLost(t *testing.T) {
+	wm := createLineMap()
+	wm.SetMetadata("B", "population", "10")
+	wm.SetMetadata("C", "population", "50")
+	wm.AddAlien(&world.Alien{Name: "X", City: "C"})
+	wm.AddAlien(&world.Alien{Name: "Y", City: "C"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetRules(Rules{Evacuation: Evacuation{Capacity: 5}, RebuildAfter: 1})
+	result := simulator.Simulate()
+	assert.Assert(t, result.Population == Population{Initial: 60, Lost: 50, Saved: 10})
+	// the rebuilt city is empty
+	assert.Assert(t, wm.GetCities()["C"].Metadata["population"] == "0")
+}
@@ -0,0 +1,203 @@
+package simulator
+
+// EventType describes what kind of event happened during the simulation.
+type EventType int
+
+const (
+	// CityDestroyed means that aliens fought in a city and destroyed it.
+	CityDestroyed EventType = iota
+	// RoadDestroyed means that aliens met head-on on a road and destroyed it.
+	RoadDestroyed
+	// WaveArrived means that a wave of new aliens has arrived into the cities.
+	WaveArrived
+	// CityRebuilt means that a destroyed city has been rebuilt.
+	CityRebuilt
+	// RoadClosed means that a road has been closed and can't be used.
+	RoadClosed
+	// RoadReopened means that a closed road can be used again.
+	RoadReopened
+	// AliensKilled means that defenders have killed all the aliens in a city.
+	AliensKilled
+	// DefendersKilled means that aliens have killed all the defenders in a city.
+	DefendersKilled
+	// CityCollapsed means that a city has run out of hit points after a blast.
+	CityCollapsed
+	// AlienExhausted means that an alien has died after its lifespan is over.
+	AlienExhausted
+	// AlienStranded means that an alien has run out of fuel and can't move anymore.
+	AlienStranded
+	// AlienBorn means that an alien has spawned a child, Aliens contain the child and the parent.
+	AlienBorn
+	// TargetReached means that an alien has arrived into its target city.
+	TargetReached
+	// CityOccupied means that aliens have occupied a city in infection mode.
+	CityOccupied
+	// AlienConverted means that an inhabitant has been converted into an alien,
+	// Aliens contain the new alien and the converting one.
+	AlienConverted
+	// CityRecovered means that an occupied city has been left by aliens long enough to recover.
+	CityRecovered
+)
+
+func (t EventType) String() string {
+	switch t {
+	case CityDestroyed:
+		return "city destroyed"
+	case RoadDestroyed:
+		return "road destroyed"
+	case WaveArrived:
+		return "wave arrived"
+	case CityRebuilt:
+		return "city rebuilt"
+	case RoadClosed:
+		return "road closed"
+	case RoadReopened:
+		return "road reopened"
+	case AliensKilled:
+		return "aliens killed"
+	case DefendersKilled:
+		return "defenders killed"
+	case CityCollapsed:
+		return "city collapsed"
+	case AlienExhausted:
+		return "alien exhausted"
+	case AlienStranded:
+		return "alien stranded"
+	case AlienBorn:
+		return "alien born"
+	case TargetReached:
+		return "target reached"
+	case CityOccupied:
+		return "city occupied"
+	case AlienConverted:
+		return "alien converted"
+	case CityRecovered:
+		return "city recovered"
+	}
+	return "unknown event"
+}
+
+// Event is a single notable thing which happened during the simulation.
+type Event struct {
+	// Step is a simulation step when the event happened, 0 means before the first step.
+	// For the continuous engine it's the time rounded up.
+	Step uint32
+	// Time is the exact time of the event, it equals to Step for the discrete engine.
+	Time float64
+	Type EventType
+	// Cities contains the city where the event happened or both ends of the road.
+	Cities []string
+	// Aliens contains sorted names of aliens involved.
+	Aliens []string
+	// Defenders contains sorted names of defenders involved.
+	Defenders []string
+}
+
+// Reason describes why the simulation has been finished.
+type Reason int
+
+const (
+	// StepLimitReached means that all the simulation steps have been performed.
+	StepLimitReached Reason = iota
+	// NoAliensLeft means that all the aliens have been killed.
+	NoAliensLeft
+)
+
+func (r Reason) String() string {
+	switch r {
+	case StepLimitReached:
+		return "step limit reached"
+	case NoAliensLeft:
+		return "no aliens left"
+	}
+	return "unknown reason"
+}
+
+// Result summarizes the simulation.
+type Result struct {
+	// Steps is amount of performed simulation steps.
+	Steps uint32
+	// Time is the simulation time when the simulation has been finished.
+	Time   float64
+	Reason Reason
+	Events []Event
+	// AlienWaves keeps the wave every alien has arrived with. Aliens unleashed
+	// before the first step belong to the "initial" wave.
+	AlienWaves map[string]string
+	// Defenders contains final state of all the defenders sorted by name.
+	Defenders []Defender
+	// Goals contains targets of aliens and whether they have been reached sorted by alien name.
+	Goals []Goal
+	// Population contains fate of people when Rules.Evacuation is set.
+	Population Population
+	// Epidemic contains amount of susceptible, occupied and recovered cities at the end of every step
+	// when Rules.Infection is set.
+	Epidemic []SIR
+}
+
+// Count returns amount of events of the given type.
+func (r *Result) Count(eventType EventType) int {
+	count := 0
+	for _, event := range r.Events {
+		if event.Type == eventType {
+			count++
+		}
+	}
+	return count
+}
+
+// DestructionRate returns amount of destroyed cities per simulation step.
+func (r *Result) DestructionRate() float64 {
+	if r.Steps == 0 {
+		return 0
+	}
+	return float64(r.Count(CityDestroyed)) / float64(r.Steps)
+}
+
+// DestructionByWave counts destroyed cities and roads credited to every wave. A destruction
+// caused by aliens of several waves is credited to each of them.
+func (r *Result) DestructionByWave() map[string]int {
+	credits := make(map[string]int)
+	for _, event := range r.Events {
+		if event.Type != CityDestroyed && event.Type != RoadDestroyed {
+			continue
+		}
+		waves := make(map[string]bool)
+		for _, alien := range event.Aliens {
+			if wave, ok := r.AlienWaves[alien]; ok {
+				waves[wave] = true
+			}
+		}
+		for wave := range waves {
+			credits[wave]++
+		}
+	}
+	return credits
+}
+
+// CityCycles counts how many times a city has been destroyed and rebuilt.
+type CityCycles struct {
+	Destroyed int
+	Rebuilt   int
+}
+
+// CityCycles returns destroy/rebuild cycles of every city which has been destroyed at least once.
+// Collapsed cities are counted as destroyed.
+func (r *Result) CityCycles() map[string]CityCycles {
+	cycles := make(map[string]CityCycles)
+	for _, event := range r.Events {
+		if event.Type != CityDestroyed && event.Type != CityCollapsed && event.Type != CityRebuilt {
+			continue
+		}
+		for _, city := range event.Cities {
+			cycle := cycles[city]
+			if event.Type != CityRebuilt {
+				cycle.Destroyed++
+			} else {
+				cycle.Rebuilt++
+			}
+			cycles[city] = cycle
+		}
+	}
+	return cycles
+}
@@ -0,0 +1,167 @@
+package simulator
+
+import (
+	"log"
+	"sort"
+
+	"github.com/luckychess/invasion/world"
+)
+
+// RandomTarget assigns every alien a random destination instead of a given city.
+const RandomTarget = "random"
+
+// Goal is a target city of an alien and the outcome of its travel.
+type Goal struct {
+	Alien  string
+	Target string
+	// Reached is set when the alien has arrived into the target city.
+	Reached bool
+	// Replans is amount of times the route has been planned again because the previous one broke.
+	Replans int
+}
+
+// alienGoal is the goal together with the route planned to reach it.
+type alienGoal struct {
+	Goal
+	route []string
+	// stranded keeps cities without a route to the target, it's valid while openings don't change
+	stranded map[string]bool
+	openings uint32
+}
+
+// goalOf returns the goal of the alien assigning it on the first call or nil if the alien
+// wanders randomly. The target attribute of the alien overrides Rules.Target.
+func (sim *simulator) goalOf(alien *world.Alien) *alienGoal {
+	if goal, ok := sim.goals[alien.Name]; ok {
+		return goal
+	}
+	target, ok := alien.Attributes["target"]
+	if !ok {
+		target = sim.rules.Target
+	}
+	if target == "" {
+		return nil
+	}
+	if target == RandomTarget {
+		target = sim.randomTarget(alien.City)
+	}
+	goal := &alienGoal{Goal: Goal{Alien: alien.Name, Target: target}}
+	sim.goals[alien.Name] = goal
+	return goal
+}
+
+// randomTarget chooses a random existing city other than the given one.
+func (sim *simulator) randomTarget(city string) string {
+	cities := make([]string, 0)
+	for _, name := range sortedCities(sim.worldMap.GetCities()) {
+		if name != city {
+			cities = append(cities, name)
+		}
+	}
+	if len(cities) == 0 {
+		return city
+	}
+	return cities[sim.rng.Intn(len(cities))]
+}
+
+// travel moves the alien one road closer to its target along the shortest route, the route
+// is planned again when a road or a city on it disappears. Aliens without goals, aliens whose
+// target has been destroyed and aliens with no route to the target wander.
+// Aliens which have reached their target stay there.
+func (sim *simulator) travel(alien *world.Alien) {
+	if alien.Transit != nil || alien.Attributes["target"] == "" && sim.rules.Target == "" {
+		sim.wander(alien)
+		return
+	}
+	goal := sim.goalOf(alien)
+	if goal.Reached || alien.City == goal.Target {
+		return
+	}
+	if sim.worldMap.GetCities()[goal.Target] == nil {
+		sim.wander(alien)
+		return
+	}
+	if goal.route == nil || world.FollowPath(sim.worldMap, alien.City, goal.route) != goal.Target {
+		if goal.route != nil {
+			goal.Replans++
+			log.Printf("Alien %s has lost its route to %s", alien.Name, goal.Target)
+		}
+		goal.route = sim.routeTo(goal, alien.City)
+	}
+	if goal.route == nil {
+		sim.wander(alien)
+		return
+	}
+	if len(goal.route) > 0 {
+		if err := sim.worldMap.MoveAlienAlong(alien, goal.route[0]); err != nil {
+			log.Println(err)
+			return
+		}
+		goal.route = goal.route[1:]
+	}
+}
+
+// routeTo returns the shortest route from the city to the target of the goal or nil if there is none.
+// Cities without a route are remembered until a city is rebuilt or a road is reopened, so aliens
+// wandering around an unreachable target don't search for it at every step.
+func (sim *simulator) routeTo(goal *alienGoal, city string) []string {
+	if goal.openings != sim.openings {
+		goal.stranded = nil
+		goal.openings = sim.openings
+	}
+	if goal.stranded[city] {
+		return nil
+	}
+	route := world.ShortestPath(sim.worldMap, city, goal.Target)
+	if route == nil {
+		// the target can't be reached from any city reachable from this one either
+		if goal.stranded == nil {
+			goal.stranded = make(map[string]bool)
+		}
+		for name := range world.Reachable(sim.worldMap, city) {
+			goal.stranded[name] = true
+		}
+	}
+	return route
+}
+
+// wander moves the alien randomly or according to the swarm rule.
+func (sim *simulator) wander(alien *world.Alien) {
+	if sim.rules.Swarm.Mode == NoSwarm {
+		sim.moveRandomly(alien)
+		return
+	}
+	sim.swarm(alien)
+}
+
+// moveRandomly moves the alien in a random direction. Aliens which have teleported are remembered
+// for the head-on fight check, as they haven't travelled along any road.
+func (sim *simulator) moveRandomly(alien *world.Alien) {
+	if sim.worldMap.MoveAlien(alien, sim.rng) && sim.rules.HeadOnFights {
+		sim.teleported[alien.Name] = true
+	}
+}
+
+// arrive marks the goal of the alien reached if it has just arrived into its target.
+func (sim *simulator) arrive(alien *world.Alien) {
+	goal := sim.goals[alien.Name]
+	if goal == nil || goal.Reached || alien.City != goal.Target {
+		return
+	}
+	goal.Reached = true
+	goal.route = nil
+	log.Printf("Alien %s has reached its target %s", alien.Name, goal.Target)
+	sim.record(TargetReached, []string{alien.City}, []string{alien.Name})
+}
+
+// goalsReport returns goals of all the aliens sorted by alien names.
+func (sim *simulator) goalsReport() []Goal {
+	goals := make([]Goal, 0, len(sim.goals))
+	for _, goal := range sim.goals {
+		goals = append(goals, goal.Goal)
+	}
+	sort.Slice(goals, func(i, j int) bool {
+		return goals[i].Alien < goals[j].Alien
+	})
+	return goals
+}
@@ -0,0 +1,65 @@
+package simulator
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/luckychess/invasion/world"
+	"gotest.tools/v3/assert"
+)
+
+func TestAlienReachesTarget(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddRoad("A", "east", "B", world.RoadOptions{})
+	wm.AddRoad("B", "east", "C", world.RoadOptions{})
+	wm.AddRoad("A", "north", "D", world.RoadOptions{})
+	wm.AddRoad("D", "east", "E", world.RoadOptions{})
+	wm.AddRoad("E", "south", "C", world.RoadOptions{})
+	wm.AddAlien(&world.Alien{Name: "X", City: "A"})
+	wm.AddCity("F", nil)
+	wm.AddAlien(&world.Alien{Name: "Y", City: "F", Attributes: map[string]string{"target": "F"}})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(10)
+	simulator.SetRules(Rules{Target: "C"})
+	// the road from B to C is closed after the alien has arrived into B, so it has to go around
+	simulator.Schedule(2, CloseRoad{City: "B", Direction: "east"})
+	result := simulator.Simulate()
+	assert.DeepEqual(t, result.Goals, []Goal{{Alien: "X", Target: "C", Reached: true, Replans: 1}, {Alien: "Y", Target: "F", Reached: true}})
+	assert.Assert(t, wm.GetAliens()["X"].City == "C")
+	// Y has been in its target from the beginning
+	assert.Assert(t, result.Count(TargetReached) == 2)
+	assert.Assert(t, result.Events[len(result.Events)-1].Step == 5)
+}
+
+func TestAlienTargetDestroyed(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddRoad("A", "east", "B", world.RoadOptions{})
+	wm.AddRoad("B", "east", "C", world.RoadOptions{})
+	wm.AddAlien(&world.Alien{Name: "X", City: "A"})
+	wm.AddAlien(&world.Alien{Name: "Y", City: "C"})
+	wm.AddAlien(&world.Alien{Name: "Z", City: "C"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(3)
+	simulator.SetRules(Rules{Target: "C"})
+	result := simulator.Simulate()
+	// C is destroyed before the first step so X wanders between A and B
+	assert.DeepEqual(t, result.Goals, []Goal{{Alien: "X", Target: "C"}})
+	assert.Assert(t, result.Count(TargetReached) == 0)
+}
+
+func TestUnreachableTargetRemembered(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddRoad("A", "east", "B", world.RoadOptions{})
+	wm.AddRoad("B", "east", "C", world.RoadOptions{})
+	wm.CloseRoad("B", "east")
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	goal := &alienGoal{Goal: Goal{Alien: "X", Target: "C"}}
+	assert.Assert(t, simulator.routeTo(goal, "A") == nil)
+	assert.DeepEqual(t, goal.stranded, map[string]bool{"A": true, "B": true})
+	// the road reopened behind the back of the simulator is not noticed
+	wm.ReopenRoad("B", "east")
+	assert.Assert(t, simulator.routeTo(goal, "B") == nil)
+	wm.CloseRoad("B", "east")
+	assert.NilError(t, ReopenRoad{City: "B", Direction: "east"}.apply(&simulator))
+	assert.DeepEqual(t, simulator.routeTo(goal, "A"), []string{"east", "east"})
+}
@@ -0,0 +1,138 @@
+package simulator
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// Infection replaces destruction of cities with their occupation. Aliens never fight each other,
+// every alien in a city tries to convert its inhabitants into new aliens whenever the city
+// is checked for a fight, i.e. after arrivals. Occupied cities left by all the aliens recover
+// after a while and become immune.
+type Infection struct {
+	// Probability of an alien to convert inhabitants of its city during a check, 0 disables infection.
+	Probability float64
+	// Converts is amount of inhabitants converted at once, 1 by default.
+	Converts uint32
+	// RecoverAfter is amount of steps without aliens after which an occupied city recovers, 0 means never.
+	RecoverAfter uint32
+}
+
+// SIR is amount of susceptible, occupied and recovered cities at the end of a step.
+type SIR struct {
+	Step        uint32
+	Susceptible int
+	Occupied    int
+	Recovered   int
+}
+
+// ParseInfection converts a comma separated list of options into infection rules,
+// e.g. "chance:0.3,converts:2,recover:10".
+func ParseInfection(spec string) (Infection, error) {
+	infection := Infection{}
+	for _, option := range strings.Split(spec, ",") {
+		parts := strings.SplitN(option, ":", 2)
+		if len(parts) != 2 {
+			return infection, fmt.Errorf("unknown infection option %s", option)
+		}
+		var err error
+		switch parts[0] {
+		case "chance":
+			infection.Probability, err = strconv.ParseFloat(parts[1], 64)
+			if err != nil || infection.Probability <= 0 || infection.Probability > 1 {
+				err = fmt.Errorf("expected infection chance above 0 and up to 1 but got %s", parts[1])
+			}
+		case "converts":
+			infection.Converts, err = parseCount(parts[1])
+		case "recover":
+			infection.RecoverAfter, err = parseCount(parts[1])
+		default:
+			err = fmt.Errorf("unknown infection option %s", option)
+		}
+		if err != nil {
+			return infection, err
+		}
+	}
+	if infection.Probability == 0 {
+		return infection, fmt.Errorf("infection chance is not set")
+	}
+	return infection, nil
+}
+
+// infect occupies the city if there are aliens in it and lets every alien try to convert inhabitants.
+// Recovered cities are immune.
+func (sim *simulator) infect(name string) {
+	city := sim.worldMap.GetCities()[name]
+	if city == nil || len(city.Aliens) == 0 || sim.recovered[name] {
+		return
+	}
+	aliens := make([]string, 0, len(city.Aliens))
+	for alien := range city.Aliens {
+		aliens = append(aliens, alien)
+	}
+	aliens = uniqueSorted(aliens)
+	if _, occupied := sim.occupied[name]; !occupied {
+		log.Printf("%s has been occupied by aliens %s", name, strings.Join(aliens, " "))
+		sim.record(CityOccupied, []string{name}, aliens)
+	}
+	sim.occupied[name] = sim.step
+	converts := uint64(sim.rules.Infection.Converts)
+	if converts == 0 {
+		converts = 1
+	}
+	for _, alien := range aliens {
+		people := populationOf(name, city.Metadata)
+		if people == 0 {
+			return
+		}
+		if sim.rng.Float64() >= sim.rules.Infection.Probability {
+			continue
+		}
+		if converts > people {
+			converts = people
+		}
+		sim.worldMap.SetMetadata(name, populationKey, strconv.FormatUint(people-converts, 10))
+		parent := sim.worldMap.GetAliens()[alien]
+		for i := uint64(0); i < converts; i++ {
+			child := sim.spawnOffspring(parent, name)
+			log.Printf("Alien %s has converted an inhabitant of %s into alien %s", alien, name, child)
+			sim.record(AlienConverted, []string{name}, []string{child, alien})
+		}
+	}
+}
+
+// census recovers occupied cities left by aliens long enough and remembers amount of
+// susceptible, occupied and recovered cities at the end of the previous step.
+func (sim *simulator) census(step uint32) {
+	if sim.rules.Infection.Probability == 0 {
+		return
+	}
+	if len(sim.epidemic) > 0 && sim.epidemic[len(sim.epidemic)-1].Step >= step {
+		return
+	}
+	cities := sim.worldMap.GetCities()
+	sir := SIR{Step: step}
+	for _, name := range sortedCities(cities) {
+		lastSeen, occupied := sim.occupied[name]
+		switch {
+		case sim.recovered[name]:
+			sir.Recovered++
+		case !occupied:
+			sir.Susceptible++
+		case len(cities[name].Aliens) > 0:
+			sim.occupied[name] = step
+			sir.Occupied++
+		case sim.rules.Infection.RecoverAfter > 0 && step-lastSeen >= sim.rules.Infection.RecoverAfter:
+			delete(sim.occupied, name)
+			sim.recovered[name] = true
+			log.Printf("%s has recovered", name)
+			sim.record(CityRecovered, []string{name}, nil)
+			sir.Recovered++
+		default:
+			sir.Occupied++
+		}
+	}
+	sim.epidemic = append(sim.epidemic, sir)
+}
@@ -0,0 +1,63 @@
+package simulator
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/luckychess/invasion/world"
+	"gotest.tools/v3/assert"
+)
+
+func TestInfection(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.SetMetadata("B", "population", "3")
+	wm.AddAlien(&world.Alien{Name: "X", City: "A"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(2)
+	simulator.SetRules(Rules{Infection: Infection{Probability: 1, Converts: 2}})
+	result := simulator.Simulate()
+	// X converts two inhabitants of B, then all the aliens move back to A without any fight
+	assert.Assert(t, result.Count(CityOccupied) == 2)
+	assert.Assert(t, result.Count(AlienConverted) == 2)
+	assert.Assert(t, result.Count(CityDestroyed) == 0)
+	assert.Assert(t, len(wm.GetCities()["A"].Aliens) == 3)
+	assert.Assert(t, wm.GetCities()["B"].Metadata["population"] == "1")
+	assert.DeepEqual(t, result.Epidemic, []SIR{
+		{Step: 0, Susceptible: 1, Occupied: 1},
+		{Step: 1, Occupied: 2},
+		{Step: 2, Occupied: 2},
+	})
+}
+
+func TestRecovery(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetRules(Rules{Infection: Infection{Probability: 1, RecoverAfter: 2}})
+	simulator.occupied["A"] = 0
+	simulator.census(1)
+	simulator.census(2)
+	// recovered cities are immune
+	wm.AddAlien(&world.Alien{Name: "X", City: "A"})
+	simulator.infect("A")
+	simulator.census(3)
+	assert.DeepEqual(t, simulator.epidemic, []SIR{
+		{Step: 1, Susceptible: 1, Occupied: 1},
+		{Step: 2, Susceptible: 1, Recovered: 1},
+		{Step: 3, Susceptible: 1, Recovered: 1},
+	})
+	assert.Assert(t, simulator.events[0].Type == CityRecovered)
+}
+
+func TestParseInfection(t *testing.T) {
+	infection, err := ParseInfection("chance:0.5,converts:3,recover:10")
+	assert.NilError(t, err)
+	assert.Assert(t, infection == Infection{Probability: 0.5, Converts: 3, RecoverAfter: 10})
+	_, err = ParseInfection("converts:3")
+	assert.Error(t, err, "infection chance is not set")
+	_, err = ParseInfection("chance:2")
+	assert.Error(t, err, "expected infection chance above 0 and up to 1 but got 2")
+	_, err = ParseInfection("chance:0.1,immunity")
+	assert.Error(t, err, "unknown infection option immunity")
+}
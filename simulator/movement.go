@@ -0,0 +1,129 @@
+package simulator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/luckychess/invasion/world"
+)
+
+// MovementOrder defines how aliens move within a simulation step.
+type MovementOrder int
+
+const (
+	// Simultaneous moves all the aliens first and checks for fights only after that.
+	Simultaneous MovementOrder = iota
+	// SequentialRandom moves aliens one by one in a random order.
+	// Every arrival is checked for a fight immediately.
+	SequentialRandom
+	// SequentialSorted moves aliens one by one in order of their names.
+	// Every arrival is checked for a fight immediately.
+	SequentialSorted
+	// Asynchronous activates randomly chosen aliens one at a time as many times as there are
+	// aliens alive at the beginning of the step, so some aliens may move several times and
+	// some may not move at all. This approximates continuous time where every alien moves
+	// once per step on average. Every arrival is checked for a fight immediately.
+	Asynchronous
+)
+
+var movementOrderNames = map[MovementOrder]string{
+	Simultaneous:     "simultaneous",
+	SequentialRandom: "sequential-random",
+	SequentialSorted: "sequential-sorted",
+	Asynchronous:     "async",
+}
+
+func (o MovementOrder) String() string {
+	if name, ok := movementOrderNames[o]; ok {
+		return name
+	}
+	return "unknown movement order"
+}
+
+// ParseMovementOrder converts a movement order name into MovementOrder.
+func ParseMovementOrder(name string) (MovementOrder, error) {
+	for order, orderName := range movementOrderNames {
+		if orderName == name {
+			return order, nil
+		}
+	}
+	return Simultaneous, fmt.Errorf("unknown movement order %s", name)
+}
+
+func (sim *simulator) moveSimultaneously() {
+	var before map[string]position
+	if sim.rules.HeadOnFights {
+		before = sim.positions()
+	}
+	// aliens move in order of their names to make the simulation reproducible with the same seed
+	aliens := sim.worldMap.GetAliens()
+	for _, name := range sortedNames(aliens) {
+		sim.moveAlien(aliens[name])
+	}
+	if sim.rules.HeadOnFights {
+		sim.fightOnRoads(before)
+	}
+	sim.fightAliens()
+}
+
+func (sim *simulator) moveSequentially() {
+	names := sim.alienNames()
+	if sim.rules.Movement == SequentialRandom {
+		sim.rng.Shuffle(len(names), func(i, j int) {
+			names[i], names[j] = names[j], names[i]
+		})
+	}
+	for _, name := range names {
+		// the alien could have been killed by somebody who moved earlier
+		if alien := sim.worldMap.GetAliens()[name]; alien != nil {
+			sim.moveAndFight(alien)
+		}
+	}
+}
+
+func (sim *simulator) moveAsynchronously() {
+	alive := sim.alienNames()
+	for activations := len(alive); activations > 0 && len(alive) > 0; {
+		i := sim.rng.Intn(len(alive))
+		alien := sim.worldMap.GetAliens()[alive[i]]
+		if alien == nil {
+			// killed aliens are not counted as activations
+			alive[i] = alive[len(alive)-1]
+			alive = alive[:len(alive)-1]
+			continue
+		}
+		sim.moveAndFight(alien)
+		activations--
+	}
+}
+
+// moveAndFight moves a single alien and immediately checks for fights on its way,
+// then the policy reacts to the outcome.
+func (sim *simulator) moveAndFight(alien *world.Alien) {
+	var before map[string]position
+	if sim.rules.HeadOnFights {
+		before = map[string]position{alien.Name: positionOf(alien)}
+	}
+	sim.moveAlien(alien)
+	if sim.rules.HeadOnFights {
+		sim.fightOnRoads(before)
+	}
+	if sim.worldMap.GetAliens()[alien.Name] != nil && alien.Transit == nil {
+		sim.fightIn(alien.City)
+	}
+	sim.react()
+}
+
+// alienNames returns sorted names of all the aliens alive.
+func (sim *simulator) alienNames() []string {
+	return sortedNames(sim.worldMap.GetAliens())
+}
+
+func sortedNames(aliens map[string]*world.Alien) []string {
+	names := make([]string, 0, len(aliens))
+	for name := range aliens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,75 @@
+package simulator
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/luckychess/invasion/world"
+	"gotest.tools/v3/assert"
+)
+
+// createSwapMap creates 2 connected cities with an alien in each of them.
+func createSwapMap() world.WorldMap {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddAlien(&world.Alien{Name: "X", City: "A"})
+	wm.AddAlien(&world.Alien{Name: "Y", City: "B"})
+	return wm
+}
+
+func TestParseMovementOrder(t *testing.T) {
+	for _, order := range []MovementOrder{Simultaneous, SequentialRandom, SequentialSorted, Asynchronous} {
+		parsed, err := ParseMovementOrder(order.String())
+		assert.NilError(t, err)
+		assert.Assert(t, parsed == order)
+	}
+	_, err := ParseMovementOrder("teleport")
+	assert.Error(t, err, "unknown movement order teleport")
+}
+
+func TestSimultaneousMovementSwap(t *testing.T) {
+	wm := createSwapMap()
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	result := simulator.Simulate()
+	// aliens swap cities every step and never fight
+	assert.Assert(t, len(result.Events) == 0)
+	assert.Assert(t, len(wm.GetAliens()) == 2)
+}
+
+func TestSequentialSortedMovement(t *testing.T) {
+	wm := createSwapMap()
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetRules(Rules{Movement: SequentialSorted})
+	result := simulator.Simulate()
+	// X moves first and finds Y in B
+	assert.Assert(t, result.Reason == NoAliensLeft)
+	assert.DeepEqual(t, result.Events, []Event{{Step: 1, Time: 1, Type: CityDestroyed, Cities: []string{"B"}, Aliens: []string{"X", "Y"}}})
+	assert.Assert(t, wm.GetCities()["A"] != nil)
+}
+
+func TestSequentialRandomAndAsynchronousMovement(t *testing.T) {
+	for _, order := range []MovementOrder{SequentialRandom, Asynchronous} {
+		wm := createSwapMap()
+		simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+		simulator.SetRules(Rules{Movement: order})
+		result := simulator.Simulate()
+		// whoever moves first meets another alien immediately
+		assert.Assert(t, result.Reason == NoAliensLeft)
+		assert.Assert(t, len(result.Events) == 1)
+		assert.Assert(t, result.Events[0].Step == 1)
+		assert.Assert(t, result.Events[0].Type == CityDestroyed)
+		assert.Assert(t, len(wm.GetCities()) == 1)
+	}
+}
+
+func TestSequentialMovementHeadOn(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddRoad("A", "east", "B", world.RoadOptions{Length: 3})
+	wm.AddAlien(&world.Alien{Name: "X", City: "A"})
+	wm.AddAlien(&world.Alien{Name: "Y", City: "B"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetRules(Rules{Movement: SequentialSorted, HeadOnFights: true})
+	result := simulator.Simulate()
+	// at the second step X reaches the middle of the road and passes Y standing there
+	assert.DeepEqual(t, result.Events, []Event{{Step: 2, Time: 2, Type: RoadDestroyed, Cities: []string{"A", "B"}, Aliens: []string{"X", "Y"}}})
+}
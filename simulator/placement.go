@@ -0,0 +1,198 @@
+package simulator
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/luckychess/invasion/world"
+)
+
+// Placement chooses starting cities for new aliens.
+type Placement interface {
+	// Place returns a starting city for each of count aliens. Cities are chosen among
+	// candidates which are sorted names of existing cities except protected ones.
+	// Placing no aliens always succeeds, even without candidates.
+	Place(worldMap world.WorldMap, candidates []string, count int, rng *rand.Rand) ([]string, error)
+}
+
+// UniformPlacement puts every alien into a uniformly random city.
+type UniformPlacement struct{}
+
+// Place chooses cities uniformly.
+func (p UniformPlacement) Place(worldMap world.WorldMap, candidates []string, count int, rng *rand.Rand) ([]string, error) {
+	if count == 0 {
+		return nil, nil
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("there are no cities in the world")
+	}
+	cities := make([]string, count)
+	for i := range cities {
+		cities[i] = candidates[rng.Intn(len(candidates))]
+	}
+	return cities, nil
+}
+
+// WeightedPlacement puts aliens into random cities with probability proportional to the weight of the city.
+type WeightedPlacement struct {
+	Weight func(city *world.City) (float64, error)
+}
+
+// PopulationWeight uses population from the city metadata as its weight, cities without population are never chosen.
+func PopulationWeight(city *world.City) (float64, error) {
+	return city.GetMetadataFloat(populationKey, 0)
+}
+
+// DegreeWeight uses amount of roads leading out of the city as its weight.
+func DegreeWeight(city *world.City) (float64, error) {
+	return float64(len(city.GetDirections())), nil
+}
+
+// Place chooses cities proportionally to their weights.
+func (p WeightedPlacement) Place(worldMap world.WorldMap, candidates []string, count int, rng *rand.Rand) ([]string, error) {
+	if count == 0 {
+		return nil, nil
+	}
+	weights := make([]float64, len(candidates))
+	total := 0.0
+	for i, name := range candidates {
+		weight, err := p.Weight(worldMap.GetCities()[name])
+		if err != nil {
+			return nil, err
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("city %s has negative weight %g", name, weight)
+		}
+		weights[i] = weight
+		total += weight
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("there are no cities with positive weight")
+	}
+	cities := make([]string, count)
+	for i := range cities {
+		choice := rng.Float64() * total
+		for j, weight := range weights {
+			choice -= weight
+			if choice < 0 || j == len(weights)-1 {
+				cities[i] = candidates[j]
+				break
+			}
+		}
+	}
+	return cities, nil
+}
+
+// DistinctPlacement puts every alien into its own city so no fights happen right after the start.
+type DistinctPlacement struct{}
+
+// Place chooses different cities for all the aliens.
+func (p DistinctPlacement) Place(worldMap world.WorldMap, candidates []string, count int, rng *rand.Rand) ([]string, error) {
+	if count == 0 {
+		return nil, nil
+	}
+	if count > len(candidates) {
+		return nil, fmt.Errorf("can't place %d aliens into %d different cities", count, len(candidates))
+	}
+	shuffled := append([]string(nil), candidates...)
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled[:count], nil
+}
+
+// ClusteredPlacement puts aliens into random cities not further than Radius roads from any of Seeds.
+type ClusteredPlacement struct {
+	Seeds  []string
+	Radius int
+}
+
+// Place chooses cities uniformly around the seed cities.
+func (p ClusteredPlacement) Place(worldMap world.WorldMap, candidates []string, count int, rng *rand.Rand) ([]string, error) {
+	if count == 0 {
+		return nil, nil
+	}
+	cities := worldMap.GetCities()
+	distances := make(map[string]int)
+	queue := make([]string, 0)
+	for _, seed := range p.Seeds {
+		if cities[seed] == nil {
+			return nil, fmt.Errorf("seed city %s doesn't exist", seed)
+		}
+		distances[seed] = 0
+		queue = append(queue, seed)
+	}
+	for len(queue) > 0 {
+		city := cities[queue[0]]
+		queue = queue[1:]
+		if distances[city.Name] == p.Radius {
+			continue
+		}
+		for _, direction := range city.GetDirections() {
+			neighbour := city.Roads[direction].To.Name
+			if _, visited := distances[neighbour]; !visited {
+				distances[neighbour] = distances[city.Name] + 1
+				queue = append(queue, neighbour)
+			}
+		}
+	}
+	return UniformPlacement{}.Place(worldMap, filterCities(candidates, func(name string) bool {
+		_, ok := distances[name]
+		return ok
+	}), count, rng)
+}
+
+// BorderPlacement puts aliens into random border cities which have exactly one road leading out.
+type BorderPlacement struct{}
+
+// Place chooses border cities uniformly.
+func (p BorderPlacement) Place(worldMap world.WorldMap, candidates []string, count int, rng *rand.Rand) ([]string, error) {
+	if count == 0 {
+		return nil, nil
+	}
+	border := filterCities(candidates, func(name string) bool {
+		return len(worldMap.GetCities()[name].GetDirections()) == 1
+	})
+	if len(border) == 0 {
+		return nil, fmt.Errorf("there are no border cities in the world")
+	}
+	return UniformPlacement{}.Place(worldMap, border, count, rng)
+}
+
+// ParsePlacement creates a placement strategy from its description: uniform, population, degree,
+// distinct, border or cluster:<city>,<city>...:<radius>.
+func ParsePlacement(spec string) (Placement, error) {
+	switch spec {
+	case "uniform":
+		return UniformPlacement{}, nil
+	case "population":
+		return WeightedPlacement{Weight: PopulationWeight}, nil
+	case "degree":
+		return WeightedPlacement{Weight: DegreeWeight}, nil
+	case "distinct":
+		return DistinctPlacement{}, nil
+	case "border":
+		return BorderPlacement{}, nil
+	}
+	parts := strings.Split(spec, ":")
+	if len(parts) == 3 && parts[0] == "cluster" {
+		radius, err := strconv.Atoi(parts[2])
+		if err != nil || radius < 0 {
+			return nil, fmt.Errorf("cluster radius should be a non-negative number but got %s", parts[2])
+		}
+		return ClusteredPlacement{Seeds: strings.Split(parts[1], ","), Radius: radius}, nil
+	}
+	return nil, fmt.Errorf("unknown placement %s", spec)
+}
+
+func filterCities(cities []string, keep func(name string) bool) []string {
+	filtered := make([]string, 0, len(cities))
+	for _, city := range cities {
+		if keep(city) {
+			filtered = append(filtered, city)
+		}
+	}
+	return filtered
+}
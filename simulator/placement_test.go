@@ -0,0 +1,122 @@
+package simulator
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/luckychess/invasion/world"
+	"gotest.tools/v3/assert"
+)
+
+// createLineMap creates cities A - B - C - D - E connected from west to east.
+func createLineMap() world.WorldMap {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddCity("B", map[string]string{"east": "C"})
+	wm.AddCity("C", map[string]string{"east": "D"})
+	wm.AddCity("D", map[string]string{"east": "E"})
+	return wm
+}
+
+var lineCities = []string{"A", "B", "C", "D", "E"}
+
+func TestUniformPlacement(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	cities, err := UniformPlacement{}.Place(createLineMap(), lineCities, 100, rng)
+	assert.NilError(t, err)
+	assert.Assert(t, len(cities) == 100)
+	_, err = UniformPlacement{}.Place(createLineMap(), nil, 1, rng)
+	assert.Error(t, err, "there are no cities in the world")
+}
+
+func TestWeightedPlacement(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	wm := createLineMap()
+	wm.SetMetadata("C", "population", "1000")
+	cities, err := WeightedPlacement{Weight: PopulationWeight}.Place(wm, lineCities, 10, rng)
+	assert.NilError(t, err)
+	for _, city := range cities {
+		assert.Assert(t, city == "C")
+	}
+	wm.SetMetadata("C", "population", "many")
+	_, err = WeightedPlacement{Weight: PopulationWeight}.Place(wm, lineCities, 10, rng)
+	assert.Error(t, err, "city C has non-numeric population: many")
+	_, err = WeightedPlacement{Weight: PopulationWeight}.Place(createLineMap(), lineCities, 10, rng)
+	assert.Error(t, err, "there are no cities with positive weight")
+
+	// middle cities have twice more roads than border ones
+	counts := make(map[string]int)
+	cities, err = WeightedPlacement{Weight: DegreeWeight}.Place(createLineMap(), lineCities, 8000, rng)
+	assert.NilError(t, err)
+	for _, city := range cities {
+		counts[city]++
+	}
+	assert.Assert(t, counts["C"] > counts["A"]*3/2)
+}
+
+func TestDistinctPlacement(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	cities, err := DistinctPlacement{}.Place(createLineMap(), lineCities, 5, rng)
+	assert.NilError(t, err)
+	sort.Strings(cities)
+	assert.DeepEqual(t, cities, lineCities)
+	_, err = DistinctPlacement{}.Place(createLineMap(), lineCities, 6, rng)
+	assert.Error(t, err, "can't place 6 aliens into 5 different cities")
+}
+
+func TestClusteredPlacement(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	cities, err := ClusteredPlacement{Seeds: []string{"A"}, Radius: 1}.Place(createLineMap(), lineCities, 50, rng)
+	assert.NilError(t, err)
+	for _, city := range cities {
+		assert.Assert(t, city == "A" || city == "B")
+	}
+	_, err = ClusteredPlacement{Seeds: []string{"Z"}, Radius: 1}.Place(createLineMap(), lineCities, 50, rng)
+	assert.Error(t, err, "seed city Z doesn't exist")
+}
+
+func TestBorderPlacement(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	cities, err := BorderPlacement{}.Place(createLineMap(), lineCities, 50, rng)
+	assert.NilError(t, err)
+	for _, city := range cities {
+		assert.Assert(t, city == "A" || city == "E")
+	}
+	_, err = BorderPlacement{}.Place(createLineMap(), []string{"B", "C"}, 1, rng)
+	assert.Error(t, err, "there are no border cities in the world")
+}
+
+func TestPlacementWithoutAliens(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	placements := []Placement{UniformPlacement{}, WeightedPlacement{Weight: PopulationWeight}, DistinctPlacement{},
+		ClusteredPlacement{Seeds: []string{"X"}}, BorderPlacement{}}
+	for _, placement := range placements {
+		cities, err := placement.Place(createLineMap(), nil, 0, rng)
+		assert.NilError(t, err)
+		assert.Assert(t, len(cities) == 0)
+	}
+}
+
+func TestParsePlacement(t *testing.T) {
+	placement, err := ParsePlacement("cluster:A,B:3")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, placement, Placement(ClusteredPlacement{Seeds: []string{"A", "B"}, Radius: 3}))
+	placement, err = ParsePlacement("border")
+	assert.NilError(t, err)
+	assert.Equal(t, placement, Placement(BorderPlacement{}))
+	_, err = ParsePlacement("cluster:A:far")
+	assert.Error(t, err, "cluster radius should be a non-negative number but got far")
+	_, err = ParsePlacement("everywhere")
+	assert.Error(t, err, "unknown placement everywhere")
+}
+
+func TestProtectedCities(t *testing.T) {
+	wm := createLineMap()
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 4)
+	simulator.SetRules(Rules{Placement: DistinctPlacement{}, Protected: []string{"C"}})
+	simulator.unleashAliens()
+	// every city except the protected one gets an alien and nobody fights
+	assert.Assert(t, len(wm.GetAliens()) == 4)
+	assert.Assert(t, len(wm.GetCities()["C"].Aliens) == 0)
+}
@@ -0,0 +1,165 @@
+package simulator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/luckychess/invasion/world"
+)
+
+// Policy models authorities reacting to the invasion by closing roads. It's called after every
+// fight check: once all the aliens have moved with simultaneous movement, after every single
+// move with other movement orders and after new aliens arrive.
+type Policy interface {
+	// React receives events happened since the previous call and returns roads to close.
+	// It must not change the world map itself.
+	React(worldMap world.WorldMap, events []Event) []Closure
+}
+
+// Closure is a road to close in given direction from the city.
+type Closure struct {
+	City      string
+	Direction string
+}
+
+// QuarantinePolicy closes all the roads of former neighbours of every destroyed city.
+type QuarantinePolicy struct{}
+
+func (QuarantinePolicy) React(worldMap world.WorldMap, events []Event) []Closure {
+	closures := make([]Closure, 0)
+	for _, event := range events {
+		if event.Type != CityDestroyed {
+			continue
+		}
+		ruin := worldMap.GetRuins()[event.Cities[0]]
+		if ruin == nil {
+			continue
+		}
+		neighbours := make([]string, 0, len(ruin.Links))
+		for _, link := range ruin.Links {
+			if link.From == ruin.Name {
+				neighbours = append(neighbours, link.To)
+			} else {
+				neighbours = append(neighbours, link.From)
+			}
+		}
+		for _, name := range uniqueSorted(neighbours) {
+			if city := worldMap.GetCities()[name]; city != nil {
+				for _, direction := range city.GetDirections() {
+					closures = append(closures, Closure{City: name, Direction: direction})
+				}
+			}
+		}
+	}
+	return closures
+}
+
+// CapitalPolicy closes all the roads into the capital once an alien is seen in the capital or
+// one of its neighbours. The sighting is remembered, so roads into the capital reopened later
+// are closed again. The sighting is forgotten when a new simulation starts.
+type CapitalPolicy struct {
+	Capital string
+	sighted bool
+}
+
+func (p *CapitalPolicy) React(worldMap world.WorldMap, events []Event) []Closure {
+	capital := worldMap.GetCities()[p.Capital]
+	if capital == nil {
+		return nil
+	}
+	p.sighted = p.sighted || len(capital.Aliens) > 0
+	incoming := make([]Closure, 0)
+	for _, name := range sortedCities(worldMap.GetCities()) {
+		city := worldMap.GetCities()[name]
+		for _, direction := range city.GetDirections() {
+			if city.Roads[direction].To == capital {
+				incoming = append(incoming, Closure{City: name, Direction: direction})
+				p.sighted = p.sighted || len(city.Aliens) > 0
+			}
+		}
+	}
+	for _, direction := range capital.GetDirections() {
+		p.sighted = p.sighted || len(capital.Roads[direction].To.Aliens) > 0
+	}
+	if !p.sighted {
+		return nil
+	}
+	return incoming
+}
+
+func (p *CapitalPolicy) reset() {
+	p.sighted = false
+}
+
+// statefulPolicy is a policy which remembers what it has seen during the simulation.
+type statefulPolicy interface {
+	reset()
+}
+
+// ParsePolicy converts policy description into a policy: "quarantine" or "capital:<city>".
+func ParsePolicy(spec string) (Policy, error) {
+	parts := strings.SplitN(spec, ":", 2)
+	switch parts[0] {
+	case "quarantine":
+		if len(parts) == 1 {
+			return QuarantinePolicy{}, nil
+		}
+	case "capital":
+		if len(parts) == 2 && parts[1] != "" {
+			return &CapitalPolicy{Capital: parts[1]}, nil
+		}
+		return nil, fmt.Errorf("expected capital:<city> but got %s", spec)
+	}
+	return nil, fmt.Errorf("unknown policy %s", spec)
+}
+
+// resetPolicy makes the policy forget the previous simulation.
+func (sim *simulator) resetPolicy() {
+	if policy, ok := sim.rules.Policy.(statefulPolicy); ok {
+		policy.reset()
+	}
+}
+
+// react passes events happened since the previous call to the policy and closes roads it asks for.
+func (sim *simulator) react() {
+	if sim.rules.Policy == nil {
+		return
+	}
+	events := sim.events[sim.reacted:]
+	for _, closure := range sim.rules.Policy.React(sim.worldMap, events) {
+		city := sim.worldMap.GetCities()[closure.City]
+		// the road could be closed already together with the road back
+		if city != nil && city.Roads[closure.Direction] != nil {
+			sim.closeRoad(closure.City, closure.Direction)
+		}
+	}
+	sim.reacted = len(sim.events)
+}
+
+// PolicyEffect compares cities survived with a policy against the run without it.
+type PolicyEffect struct {
+	// Saved cities have been destroyed without the policy but survived with it.
+	Saved []string
+	// Lost cities have survived without the policy but have been destroyed with it.
+	Lost []string
+}
+
+// ComparePolicy compares final world maps of the simulation without and with a policy
+// which started from the same state with the same seed.
+func ComparePolicy(baseline world.WorldMap, withPolicy world.WorldMap) PolicyEffect {
+	effect := PolicyEffect{Saved: make([]string, 0), Lost: make([]string, 0)}
+	for name := range withPolicy.GetCities() {
+		if baseline.GetCities()[name] == nil {
+			effect.Saved = append(effect.Saved, name)
+		}
+	}
+	for name := range baseline.GetCities() {
+		if withPolicy.GetCities()[name] == nil {
+			effect.Lost = append(effect.Lost, name)
+		}
+	}
+	sort.Strings(effect.Saved)
+	sort.Strings(effect.Lost)
+	return effect
+}
@@ -0,0 +1,84 @@
+package simulator
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/luckychess/invasion/world"
+	"gotest.tools/v3/assert"
+)
+
+func TestQuarantinePolicy(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddCity("B", map[string]string{"east": "C", "north": "D"})
+	wm.AddCity("C", map[string]string{"east": "E"})
+	wm.AddAlien(&world.Alien{Name: "X", City: "B"})
+	wm.AddAlien(&world.Alien{Name: "Y", City: "B"})
+	wm.DestroyCity("B")
+	closures := QuarantinePolicy{}.React(wm, []Event{
+		{Type: RoadDestroyed, Cities: []string{"A", "C"}},
+		{Type: CityDestroyed, Cities: []string{"B"}},
+	})
+	// all the roads of A, C and D are closed, A and D have no roads left after B is destroyed
+	assert.DeepEqual(t, closures, []Closure{{City: "C", Direction: "east"}})
+}
+
+func TestCapitalPolicy(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("Capital", map[string]string{"east": "B", "west": "C"})
+	wm.AddRoad("D", "north", "Capital", world.RoadOptions{OneWay: true})
+	wm.AddCity("C", map[string]string{"west": "E"})
+	policy := &CapitalPolicy{Capital: "Capital"}
+	wm.AddAlien(&world.Alien{Name: "X", City: "E"})
+	assert.Assert(t, len(policy.React(wm, nil)) == 0)
+	wm.AddAlien(&world.Alien{Name: "Y", City: "D"})
+	assert.DeepEqual(t, policy.React(wm, nil), []Closure{
+		{City: "B", Direction: "west"},
+		{City: "C", Direction: "east"},
+		{City: "D", Direction: "north"},
+	})
+	// the sighting is remembered, so the road reopened after the alien has gone is closed again
+	wm.RemoveAlien("Y")
+	wm.CloseRoad("Capital", "east")
+	wm.ReopenRoad("Capital", "east")
+	assert.DeepEqual(t, policy.React(wm, nil), []Closure{{City: "B", Direction: "west"}, {City: "C", Direction: "east"}, {City: "D", Direction: "north"}})
+	policy.reset()
+	assert.Assert(t, len(policy.React(wm, nil)) == 0)
+	// an alien in the capital itself is seen as well
+	wm.AddAlien(&world.Alien{Name: "Z", City: "Capital"})
+	assert.Assert(t, len(policy.React(wm, nil)) == 3)
+	assert.Assert(t, len((&CapitalPolicy{Capital: "Atlantis"}).React(wm, nil)) == 0)
+}
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy("quarantine")
+	assert.NilError(t, err)
+	assert.Equal(t, policy, Policy(QuarantinePolicy{}))
+	policy, err = ParsePolicy("capital:Foo")
+	assert.NilError(t, err)
+	assert.Assert(t, *policy.(*CapitalPolicy) == CapitalPolicy{Capital: "Foo"})
+	_, err = ParsePolicy("capital")
+	assert.Error(t, err, "expected capital:<city> but got capital")
+	_, err = ParsePolicy("panic")
+	assert.Error(t, err, "unknown policy panic")
+}
+
+func TestPolicySavesCities(t *testing.T) {
+	run := func(policy Policy) (world.WorldMap, Result) {
+		wm := world.InitWorldMap()
+		wm.AddCity("Capital", map[string]string{"east": "B"})
+		wm.AddAlien(&world.Alien{Name: "X", City: "Capital"})
+		wm.AddAlien(&world.Alien{Name: "Y", City: "B"})
+		simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+		simulator.SetStepLimit(10)
+		simulator.SetRules(Rules{Movement: SequentialSorted, Policy: policy})
+		return wm, simulator.Simulate()
+	}
+	baseline, _ := run(nil)
+	withPolicy, result := run(&CapitalPolicy{Capital: "Capital"})
+	// X moves into B and destroys it without the policy, the road is closed as soon as
+	// aliens are placed and keeps them apart
+	assert.DeepEqual(t, result.Events, []Event{{Step: 0, Time: 0, Type: RoadClosed, Cities: []string{"B", "Capital"}}})
+	assert.DeepEqual(t, ComparePolicy(baseline, withPolicy), PolicyEffect{Saved: []string{"B"}, Lost: []string{}})
+}
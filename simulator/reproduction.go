@@ -0,0 +1,114 @@
+package simulator
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/luckychess/invasion/world"
+)
+
+// Reproduction describes how aliens spawn children. Children are born at the beginning of a step
+// in the city of their parent or in a random neighbour, inherit attributes and the wave of
+// the parent and fight according to the usual rules.
+type Reproduction struct {
+	// Every makes an alien spawn a child every given amount of steps it survives, 0 disables it.
+	Every uint32
+	// OnVisit makes an alien spawn a child after it arrives into a city without defenders.
+	OnVisit bool
+	// Adjacent places children into a random neighbour of the parent's city if there is one.
+	Adjacent bool
+	// Cap is the maximal amount of aliens alive, no children are born when it's reached.
+	// 0 means no limit.
+	Cap uint32
+}
+
+// reproduce spawns children of all the aliens which are ready for it.
+func (sim *simulator) reproduce() {
+	rules := sim.rules.Reproduction
+	if rules.Every == 0 && !rules.OnVisit {
+		return
+	}
+	aliens := sim.worldMap.GetAliens()
+	for _, name := range sortedNames(aliens) {
+		parent := aliens[name]
+		ready := sim.visited[name]
+		if rules.Every > 0 {
+			ready = ready || sim.vitalsOf(parent).age%rules.Every == 0
+		}
+		delete(sim.visited, name)
+		if !ready || parent.Transit != nil {
+			continue
+		}
+		if rules.Cap > 0 && uint32(len(aliens)) >= rules.Cap {
+			// keep going to forget visits of the rest of aliens
+			continue
+		}
+		sim.spawnChild(parent)
+	}
+}
+
+// spawnChild adds a child of the alien into the world.
+func (sim *simulator) spawnChild(parent *world.Alien) {
+	city := parent.City
+	if sim.rules.Reproduction.Adjacent {
+		if directions := sim.worldMap.GetCities()[city].GetDirections(); len(directions) > 0 {
+			city = sim.worldMap.GetCities()[city].Roads[directions[sim.rng.Intn(len(directions))]].To.Name
+		}
+	}
+	child := sim.spawnOffspring(parent, city)
+	log.Printf("Alien %s has been born in %s from alien %s", child, city, parent.Name)
+	sim.record(AlienBorn, []string{city}, []string{child, parent.Name})
+}
+
+// spawnOffspring adds a new alien into the city which inherits attributes and the wave
+// of the parent and returns its name.
+func (sim *simulator) spawnOffspring(parent *world.Alien, city string) string {
+	child := world.Alien{Name: sim.getUniqueName(), City: city, Attributes: make(map[string]string)}
+	for key, value := range parent.Attributes {
+		child.Attributes[key] = value
+	}
+	// vital limits of the parent are not inherited
+	delete(child.Attributes, "lifespan")
+	delete(child.Attributes, "fuel")
+	if wave, ok := sim.alienWaves[parent.Name]; ok {
+		sim.alienWaves[child.Name] = wave
+	}
+	sim.worldMap.AddAlien(&child)
+	return child.Name
+}
+
+// ParseReproduction converts a comma separated list of options into reproduction rules,
+// e.g. "every:10,visit,adjacent,cap:100".
+func ParseReproduction(spec string) (Reproduction, error) {
+	reproduction := Reproduction{}
+	for _, option := range strings.Split(spec, ",") {
+		parts := strings.SplitN(option, ":", 2)
+		var err error
+		switch {
+		case parts[0] == "visit" && len(parts) == 1:
+			reproduction.OnVisit = true
+		case parts[0] == "adjacent" && len(parts) == 1:
+			reproduction.Adjacent = true
+		case parts[0] == "every" && len(parts) == 2:
+			reproduction.Every, err = parseCount(parts[1])
+		case parts[0] == "cap" && len(parts) == 2:
+			reproduction.Cap, err = parseCount(parts[1])
+		default:
+			return reproduction, fmt.Errorf("unknown reproduction option %s", option)
+		}
+		if err != nil {
+			return reproduction, err
+		}
+	}
+	return reproduction, nil
+}
+
+func parseCount(value string) (uint32, error) {
+	count, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("expected a non-negative number but got %s", value)
+	}
+	return uint32(count), nil
+}
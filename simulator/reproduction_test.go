@@ -0,0 +1,123 @@
+package simulator
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/luckychess/invasion/world"
+	"gotest.tools/v3/assert"
+)
+
+func TestReproductionEverySteps(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddAlien(&world.Alien{Name: "Parent", City: "A", Attributes: map[string]string{"species": "grey", "fuel": "0"}})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(2)
+	simulator.SetRules(Rules{Reproduction: Reproduction{Every: 2, Adjacent: true}})
+	result := simulator.Simulate()
+	// the parent moves into B at the first step and spawns a child in A at the beginning of the second one
+	assert.Assert(t, result.Count(AlienBorn) == 1)
+	born := result.Events[0]
+	assert.Assert(t, born.Step == 2)
+	assert.DeepEqual(t, born.Cities, []string{"A"})
+	assert.Assert(t, born.Aliens[1] == "Parent")
+	child := born.Aliens[0]
+	assert.DeepEqual(t, wm.GetAliens()[child].Attributes, map[string]string{"species": "grey"})
+	assert.Assert(t, result.AlienWaves[child] == "")
+}
+
+func TestReproductionIsolatedCities(t *testing.T) {
+	wm := world.InitWorldMap()
+	for _, city := range []string{"A", "B", "C"} {
+		wm.AddCity(city, map[string]string{})
+	}
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(10)
+	simulator.SetRules(Rules{Reproduction: Reproduction{Every: 1, Adjacent: true, Cap: 5}})
+	simulator.Schedule(0, Wave{Name: "seed", Count: 1, Cities: []string{"A"}})
+	simulator.Schedule(0, Wave{Name: "other", Count: 1, Cities: []string{"B"}})
+	result := simulator.Simulate()
+	// isolated cities have no neighbours so children are born next to parents and destroy the cities
+	assert.Assert(t, result.Count(AlienBorn) == 2)
+	assert.Assert(t, result.Count(CityDestroyed) == 2)
+	assert.DeepEqual(t, result.DestructionByWave(), map[string]int{"seed": 1, "other": 1})
+	assert.Assert(t, wm.GetCities()["C"] != nil)
+}
+
+func TestReproductionPopulationCap(t *testing.T) {
+	wm := createLineMap()
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(1)
+	simulator.SetRules(Rules{Reproduction: Reproduction{Every: 1, Adjacent: true, Cap: 3}})
+	simulator.Schedule(0, SpawnAlien{Name: "X", City: "A"})
+	simulator.Schedule(0, SpawnAlien{Name: "Y", City: "C"})
+	simulator.Schedule(0, SpawnAlien{Name: "Z", City: "E"})
+	result := simulator.Simulate()
+	// the population cap is reached already
+	assert.Assert(t, result.Count(AlienBorn) == 0)
+}
+
+func TestReproductionCapReachedMidway(t *testing.T) {
+	wm := createLineMap()
+	for _, alien := range []world.Alien{{Name: "X", City: "A"}, {Name: "Y", City: "C"}, {Name: "Z", City: "E"}} {
+		alien := alien
+		wm.AddAlien(&alien)
+	}
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetRules(Rules{Reproduction: Reproduction{OnVisit: true, Cap: 4}})
+	simulator.visited = map[string]bool{"X": true, "Y": true, "Z": true}
+	simulator.reproduce()
+	// X spawns the only child allowed by the cap, visits of Y and Z are forgotten anyway
+	assert.Assert(t, len(simulator.events) == 1)
+	assert.Assert(t, simulator.events[0].Aliens[1] == "X")
+	assert.Assert(t, len(simulator.visited) == 0)
+}
+
+func TestReproductionOnVisit(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddAlien(&world.Alien{Name: "Visitor", City: "A"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(2)
+	simulator.SetRules(Rules{Reproduction: Reproduction{OnVisit: true, Cap: 2}})
+	simulator.AddDefender(Defender{Name: "Guard", City: "A", Strength: 10})
+	simulator.AddDefender(Defender{Name: "Hunter", City: "B", Strategy: HuntStrategy{}})
+	result := simulator.Simulate()
+	// the hunter moves into A and kills the visitor there, no children are born at the defended city
+	assert.Assert(t, result.Count(AlienBorn) == 0)
+	assert.Assert(t, result.Count(AliensKilled) == 1)
+
+	wm = world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddAlien(&world.Alien{Name: "Visitor", City: "A"})
+	simulator = InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(2)
+	simulator.SetRules(Rules{Reproduction: Reproduction{OnVisit: true}})
+	result = simulator.Simulate()
+	// the visitor arrives into undefended B at the first step and spawns a child there at the second one
+	assert.Assert(t, result.Count(AlienBorn) >= 1)
+	assert.DeepEqual(t, result.Events[0], Event{Step: 2, Time: 2, Type: AlienBorn, Cities: []string{"B"}, Aliens: result.Events[0].Aliens})
+	assert.Assert(t, result.Events[0].Aliens[1] == "Visitor")
+}
+
+func TestUniqueNames(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	first := simulator.getUniqueName()
+	simulator.alienWaves[first] = initialWave
+	// the same seed generates the same name first but it's used already
+	simulator.rng = rand.New(rand.NewSource(0))
+	assert.Assert(t, simulator.getUniqueName() != first)
+}
+
+func TestParseReproduction(t *testing.T) {
+	reproduction, err := ParseReproduction("every:10,visit,adjacent,cap:100")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, reproduction, Reproduction{Every: 10, OnVisit: true, Adjacent: true, Cap: 100})
+	_, err = ParseReproduction("every:often")
+	assert.Error(t, err, "expected a non-negative number but got often")
+	_, err = ParseReproduction("visit:1")
+	assert.Error(t, err, "unknown reproduction option visit:1")
+}
@@ -0,0 +1,283 @@
+package simulator
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/luckychess/invasion/world"
+)
+
+// Action is something scheduled to happen at the beginning of a simulation step.
+type Action interface {
+	apply(sim *simulator) error
+}
+
+// SpawnAlien puts a new alien into the city. The city is checked for a fight immediately.
+type SpawnAlien struct {
+	Name       string
+	City       string
+	Attributes map[string]string
+}
+
+func (a SpawnAlien) apply(sim *simulator) error {
+	if sim.worldMap.GetAliens()[a.Name] != nil {
+		return fmt.Errorf("alien %s already exists", a.Name)
+	}
+	alien := world.Alien{Name: a.Name, City: a.City, Attributes: a.Attributes}
+	if err := sim.worldMap.AddAlien(&alien); err != nil {
+		return err
+	}
+	log.Printf("Alien %s has arrived into city %s", a.Name, a.City)
+	if wave, ok := a.Attributes["wave"]; ok {
+		sim.alienWaves[a.Name] = wave
+	}
+	sim.fightIn(a.City)
+	sim.react()
+	return nil
+}
+
+// Wave brings a group of new aliens into the world. Aliens arrive into random entry cities
+// if they are given, otherwise cities are chosen by the placement rule. Destruction caused
+// by these aliens is credited to the wave in the result.
+type Wave struct {
+	Name   string
+	Count  uint32
+	Cities []string
+}
+
+func (w Wave) apply(sim *simulator) error {
+	var cities []string
+	var err error
+	if len(w.Cities) > 0 {
+		existing := sim.worldMap.GetCities()
+		entries := filterCities(w.Cities, func(name string) bool {
+			return existing[name] != nil
+		})
+		if len(entries) == 0 {
+			return fmt.Errorf("all entry cities of wave %s are destroyed", w.Name)
+		}
+		cities, err = UniformPlacement{}.Place(sim.worldMap, entries, int(w.Count), sim.rng)
+	} else {
+		cities, err = sim.placement().Place(sim.worldMap, sim.placementCandidates(), int(w.Count), sim.rng)
+	}
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(cities))
+	for _, city := range cities {
+		alien := world.Alien{Name: sim.getUniqueName(), City: city, Attributes: map[string]string{"wave": w.Name}}
+		sim.worldMap.AddAlien(&alien)
+		sim.alienWaves[alien.Name] = w.Name
+		names = append(names, alien.Name)
+	}
+	log.Printf("Wave %s of %d aliens has arrived", w.Name, len(names))
+	sort.Strings(names)
+	sim.record(WaveArrived, uniqueSorted(cities), names)
+	for _, city := range uniqueSorted(cities) {
+		sim.fightIn(city)
+	}
+	sim.react()
+	return nil
+}
+
+// Schedule plans the action to happen at the beginning of the given step.
+// Actions scheduled for step 0 happen right after aliens are unleashed.
+func (sim *simulator) Schedule(step uint32, action Action) {
+	if sim.schedule == nil {
+		sim.schedule = make(map[uint32][]Action)
+	}
+	sim.schedule[step] = append(sim.schedule[step], action)
+}
+
+// SchedulePeriodic plans the action to happen at the beginning of the start step and then
+// every period steps. Times limits amount of repetitions, 0 means until the end of the simulation.
+func (sim *simulator) SchedulePeriodic(start uint32, period uint32, times uint32, action Action) {
+	if period == 0 {
+		period, times = 1, 1
+	}
+	sim.periodic = append(sim.periodic, periodicAction{start: start, period: period, times: times, action: action})
+}
+
+// periodicAction is an action repeated with a constant period.
+type periodicAction struct {
+	start  uint32
+	period uint32
+	times  uint32
+	action Action
+}
+
+// occursAt checks whether the periodic action happens at the given step.
+func (p periodicAction) occursAt(step uint32) bool {
+	if step < p.start || (step-p.start)%p.period != 0 {
+		return false
+	}
+	return p.times == 0 || (step-p.start)/p.period < p.times
+}
+
+// nextActionStep returns the first step after the given one which has scheduled actions
+// and doesn't exceed the step limit.
+func (sim *simulator) nextActionStep(after uint32) (uint32, bool) {
+	next, found := uint32(0), false
+	consider := func(step uint32) {
+		if step > after && step <= sim.stepsCount && (!found || step < next) {
+			next, found = step, true
+		}
+	}
+	for step := range sim.schedule {
+		consider(step)
+	}
+	for _, p := range sim.periodic {
+		step := p.start
+		if after >= p.start {
+			step = p.start + ((after-p.start)/p.period+1)*p.period
+		}
+		if p.occursAt(step) {
+			consider(step)
+		}
+	}
+	return next, found
+}
+
+// hasPendingActions checks whether some actions are scheduled after the current step.
+func (sim *simulator) hasPendingActions() bool {
+	if len(sim.schedule) == 0 && len(sim.periodic) == 0 {
+		return false
+	}
+	_, found := sim.nextActionStep(sim.step)
+	return found
+}
+
+// applyScheduled applies all the actions scheduled for the given step in order they were scheduled,
+// periodic actions go after the ones scheduled for this step only.
+func (sim *simulator) applyScheduled(step uint32) {
+	actions := append([]Action(nil), sim.schedule[step]...)
+	for _, p := range sim.periodic {
+		if p.occursAt(step) {
+			actions = append(actions, p.action)
+		}
+	}
+	for _, action := range actions {
+		if err := action.apply(sim); err != nil {
+			log.Printf("Scheduled action at step %d failed: %s", step, err)
+		}
+	}
+}
+
+// Rebuild restores a destroyed city with its roads to existing neighbours.
+type Rebuild struct {
+	City string
+}
+
+func (r Rebuild) apply(sim *simulator) error {
+	if err := sim.worldMap.RebuildCity(r.City); err != nil {
+		return err
+	}
+	sim.openings++
+	sim.record(CityRebuilt, []string{r.City}, nil)
+	return nil
+}
+
+// CloseRoad closes the road in given direction from the city together with the road back.
+type CloseRoad struct {
+	City      string
+	Direction string
+}
+
+func (c CloseRoad) apply(sim *simulator) error {
+	return sim.closeRoad(c.City, c.Direction)
+}
+
+// ReopenRoad reopens the closed road in given direction from the city together with the road back.
+type ReopenRoad struct {
+	City      string
+	Direction string
+}
+
+func (r ReopenRoad) apply(sim *simulator) error {
+	city := sim.worldMap.GetCities()[r.City]
+	if city == nil || city.Closed[r.Direction] == nil {
+		return fmt.Errorf("no closed road from %s in %s direction", r.City, r.Direction)
+	}
+	neighbour := city.Closed[r.Direction].To.Name
+	if err := sim.worldMap.ReopenRoad(r.City, r.Direction); err != nil {
+		return err
+	}
+	sim.openings++
+	log.Printf("Road between %s and %s has been reopened", r.City, neighbour)
+	sim.record(RoadReopened, uniqueSorted([]string{r.City, neighbour}), nil)
+	return nil
+}
+
+// closeRoad closes the road and plans its repair if Rules.RoadRepairAfter is set.
+func (sim *simulator) closeRoad(city string, direction string) error {
+	from := sim.worldMap.GetCities()[city]
+	if from == nil || from.Roads[direction] == nil {
+		return fmt.Errorf("no open road from %s in %s direction", city, direction)
+	}
+	road := from.Roads[direction]
+	if err := sim.worldMap.CloseRoad(city, direction); err != nil {
+		return err
+	}
+	log.Printf("Road between %s and %s has been closed", city, road.To.Name)
+	sim.record(RoadClosed, uniqueSorted([]string{city, road.To.Name}), nil)
+	if sim.rules.RoadRepairAfter > 0 {
+		sim.Schedule(sim.step+sim.rules.RoadRepairAfter, ReopenRoad{City: city, Direction: direction})
+	}
+	return nil
+}
+
+// failRoads closes every open road with probability Rules.RoadFailure. Two-way roads
+// are checked once from the city which name goes first.
+func (sim *simulator) failRoads() {
+	if sim.rules.RoadFailure <= 0 {
+		return
+	}
+	cities := sim.worldMap.GetCities()
+	for _, name := range sortedCities(cities) {
+		city := cities[name]
+		for _, direction := range city.GetDirections() {
+			road := city.Roads[direction]
+			if road == nil || city.IsTwoWay(direction) && road.To.Name < name {
+				// already closed together with the road back or checked from the other end
+				continue
+			}
+			if sim.rng.Float64() < sim.rules.RoadFailure {
+				sim.closeRoad(name, direction)
+			}
+		}
+	}
+}
+
+// fightIn checks the city for a fight. Defenders in the city engage aliens first.
+// With Rules.Infection aliens occupy the city instead of destroying it.
+// Destruction kills people of the city, plans its reconstruction if Rules.RebuildAfter
+// is set and causes a blast if Rules.Blast is set.
+func (sim *simulator) fightIn(city string) {
+	sim.engage(city)
+	if sim.rules.Infection.Probability > 0 {
+		sim.infect(city)
+		return
+	}
+	if aliens := sim.worldMap.DestroyCity(city); len(aliens) > 0 {
+		sim.record(CityDestroyed, []string{city}, aliens)
+		sim.bury(city)
+		if sim.rules.RebuildAfter > 0 {
+			sim.Schedule(sim.step+sim.rules.RebuildAfter, Rebuild{City: city})
+		}
+		sim.blast(city, aliens)
+	}
+}
+
+func uniqueSorted(values []string) []string {
+	unique := make(map[string]bool)
+	for _, value := range values {
+		unique[value] = true
+	}
+	result := make([]string, 0, len(unique))
+	for value := range unique {
+		result = append(result, value)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,195 @@
+package simulator
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/luckychess/invasion/world"
+	"gotest.tools/v3/assert"
+)
+
+func TestScheduleSpawnAlien(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("Solo", map[string]string{})
+	wm.AddAlien(&world.Alien{Name: "Lonely", City: "Solo"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.Schedule(3, SpawnAlien{Name: "Late", City: "Solo"})
+	result := simulator.Simulate()
+	assert.Assert(t, result.Reason == NoAliensLeft)
+	assert.DeepEqual(t, result.Events, []Event{{Step: 3, Time: 3, Type: CityDestroyed, Cities: []string{"Solo"}, Aliens: []string{"Late", "Lonely"}}})
+}
+
+func TestScheduleKeepsSimulationRunning(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(20)
+	simulator.Schedule(5, SpawnAlien{Name: "Scout", City: "A", Attributes: map[string]string{"species": "grey"}})
+	// spawning into a non-existing city or with a duplicate name fails without stopping the simulation
+	simulator.Schedule(6, SpawnAlien{Name: "Lost", City: "Atlantis"})
+	simulator.Schedule(7, SpawnAlien{Name: "Scout", City: "B"})
+	result := simulator.Simulate()
+	assert.Assert(t, result.Reason == StepLimitReached)
+	assert.Assert(t, result.Steps == 20)
+	assert.Assert(t, len(wm.GetAliens()) == 1)
+	assert.Assert(t, wm.GetAliens()["Scout"].Attributes["species"] == "grey")
+}
+
+func TestScheduleContinuousEngine(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("Solo", map[string]string{})
+	wm.AddAlien(&world.Alien{Name: "Lonely", City: "Solo"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetRules(Rules{Engine: ContinuousEngine})
+	simulator.Schedule(3, SpawnAlien{Name: "Late", City: "Solo"})
+	result := simulator.Simulate()
+	// actions of step 3 happen at its beginning
+	assert.Assert(t, result.Reason == NoAliensLeft)
+	assert.DeepEqual(t, result.Events, []Event{{Step: 3, Time: 2, Type: CityDestroyed, Cities: []string{"Solo"}, Aliens: []string{"Late", "Lonely"}}})
+}
+
+func TestScheduleWave(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("Gate", map[string]string{"east": "Town"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(1)
+	simulator.Schedule(1, Wave{Name: "first", Count: 2, Cities: []string{"Gate"}})
+	result := simulator.Simulate()
+	assert.Assert(t, len(result.Events) == 2)
+	assert.Assert(t, result.Events[0].Type == WaveArrived)
+	assert.DeepEqual(t, result.Events[0].Cities, []string{"Gate"})
+	assert.Assert(t, result.Events[1].Type == CityDestroyed)
+	assert.DeepEqual(t, result.Events[1].Aliens, result.Events[0].Aliens)
+	assert.DeepEqual(t, result.DestructionByWave(), map[string]int{"first": 1})
+}
+
+func TestScheduleWaveFailures(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("Town", map[string]string{})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(3)
+	simulator.SetRules(Rules{Protected: []string{"Town"}})
+	// entry cities don't exist and all the cities are protected so nobody arrives
+	simulator.Schedule(1, Wave{Name: "lost", Count: 1, Cities: []string{"Atlantis"}})
+	simulator.Schedule(2, Wave{Name: "blocked", Count: 1})
+	result := simulator.Simulate()
+	assert.Assert(t, len(result.Events) == 0)
+	assert.Assert(t, len(wm.GetAliens()) == 0)
+}
+
+func TestSchedulePeriodic(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(100)
+	simulator.SchedulePeriodic(2, 5, 3, Wave{Name: "trickle", Count: 1, Cities: []string{"A"}})
+	result := simulator.Simulate()
+	// aliens arrive at steps 2 and 7 and destroy the entry city, so the wave of step 12 has nowhere
+	// to arrive and the simulation stops after it
+	assert.Assert(t, result.Reason == NoAliensLeft)
+	assert.Assert(t, result.Steps == 12)
+	steps := make([]uint32, 0)
+	for _, event := range result.Events {
+		steps = append(steps, event.Step)
+	}
+	assert.DeepEqual(t, steps, []uint32{2, 7, 7})
+	assert.Assert(t, result.Count(WaveArrived) == 2)
+	assert.Assert(t, len(wm.GetAliens()) == 0)
+}
+
+func TestNextActionStep(t *testing.T) {
+	simulator := InitSimulation(world.InitWorldMap(), rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(30)
+	simulator.Schedule(25, SpawnAlien{})
+	simulator.SchedulePeriodic(4, 10, 2, SpawnAlien{})
+	simulator.SchedulePeriodic(31, 1, 0, SpawnAlien{})
+	next := make([]uint32, 0)
+	for step, found := simulator.nextActionStep(0); found; step, found = simulator.nextActionStep(step) {
+		next = append(next, step)
+	}
+	assert.DeepEqual(t, next, []uint32{4, 14, 25})
+}
+
+func TestRebuildAfter(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("Solo", map[string]string{})
+	wm.SetMetadata("Solo", "population", "10")
+	wm.AddAlien(&world.Alien{Name: "Lonely", City: "Solo"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(20)
+	simulator.SetRules(Rules{RebuildAfter: 3})
+	simulator.Schedule(1, SpawnAlien{Name: "Late", City: "Solo"})
+	simulator.Schedule(6, SpawnAlien{Name: "Z", City: "Solo"})
+	simulator.Schedule(6, SpawnAlien{Name: "W", City: "Solo"})
+	result := simulator.Simulate()
+	// pending reconstruction keeps the simulation running until the city is rebuilt
+	assert.Assert(t, result.Reason == NoAliensLeft)
+	assert.Assert(t, result.Steps == 9)
+	assert.DeepEqual(t, result.Events, []Event{
+		{Step: 1, Time: 1, Type: CityDestroyed, Cities: []string{"Solo"}, Aliens: []string{"Late", "Lonely"}},
+		{Step: 4, Time: 4, Type: CityRebuilt, Cities: []string{"Solo"}},
+		{Step: 6, Time: 6, Type: CityDestroyed, Cities: []string{"Solo"}, Aliens: []string{"W", "Z"}},
+		{Step: 9, Time: 9, Type: CityRebuilt, Cities: []string{"Solo"}},
+	})
+	assert.DeepEqual(t, result.CityCycles(), map[string]CityCycles{"Solo": {Destroyed: 2, Rebuilt: 2}})
+	assert.Assert(t, wm.GetCities()["Solo"].Metadata["population"] == "10")
+}
+
+func TestScheduleRebuild(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("Solo", map[string]string{})
+	wm.AddAlien(&world.Alien{Name: "Lonely", City: "Solo"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.Schedule(1, SpawnAlien{Name: "Late", City: "Solo"})
+	simulator.Schedule(4, Rebuild{City: "Solo"})
+	// rebuilding an existing city fails
+	simulator.Schedule(5, Rebuild{City: "Solo"})
+	result := simulator.Simulate()
+	assert.Assert(t, result.Reason == NoAliensLeft)
+	assert.Assert(t, result.Steps == 5)
+	assert.Assert(t, wm.GetCities()["Solo"] != nil)
+	assert.DeepEqual(t, result.CityCycles(), map[string]CityCycles{"Solo": {Destroyed: 1, Rebuilt: 1}})
+}
+
+func TestScheduleRoadClosures(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(10)
+	simulator.SetRules(Rules{RoadRepairAfter: 2})
+	simulator.Schedule(1, CloseRoad{City: "B", Direction: "west"})
+	// closing a closed road and reopening an open one fail
+	simulator.Schedule(2, CloseRoad{City: "A", Direction: "east"})
+	simulator.Schedule(4, ReopenRoad{City: "A", Direction: "east"})
+	result := simulator.Simulate()
+	assert.DeepEqual(t, result.Events, []Event{
+		{Step: 1, Time: 1, Type: RoadClosed, Cities: []string{"A", "B"}},
+		{Step: 3, Time: 3, Type: RoadReopened, Cities: []string{"A", "B"}},
+	})
+	assert.Assert(t, wm.GetCities()["A"].Roads["east"] != nil)
+}
+
+func TestRoadFailures(t *testing.T) {
+	for _, engine := range []Engine{DiscreteEngine, ContinuousEngine} {
+		wm := world.InitWorldMap()
+		wm.AddCity("A", map[string]string{"east": "B", "north": "C"})
+		wm.AddRoad("B", "north", "D", world.RoadOptions{OneWay: true})
+		wm.AddAlien(&world.Alien{Name: "X", City: "A"})
+		simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+		simulator.SetStepLimit(5)
+		simulator.SetRules(Rules{Engine: engine, RoadFailure: 1})
+		result := simulator.Simulate()
+		// every road fails at the beginning of the first step so the alien is trapped,
+		// the first step of the continuous engine begins at time 0
+		time := 1.0
+		if engine == ContinuousEngine {
+			time = 0
+		}
+		assert.DeepEqual(t, result.Events, []Event{
+			{Step: 1, Time: time, Type: RoadClosed, Cities: []string{"A", "B"}},
+			{Step: 1, Time: time, Type: RoadClosed, Cities: []string{"A", "C"}},
+			{Step: 1, Time: time, Type: RoadClosed, Cities: []string{"B", "D"}},
+		})
+		assert.Assert(t, wm.GetAliens()["X"].City == "A")
+	}
+}
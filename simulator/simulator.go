@@ -1,92 +1,275 @@
 package simulator
 
 import (
-	"fmt"
 	"log"
 	"math/rand"
+	"sort"
 
 	"github.com/luckychess/invasion/world"
 )
 
 const (
 	simulatorSteps = 10000
+	// initialWave is the wave of aliens unleashed before the first step.
+	initialWave = "initial"
 )
 
+// Rules contains optional rules of the simulation. Zero value means classic rules.
+type Rules struct {
+	// HeadOnFights makes aliens traversing the same road in opposite directions during a step
+	// fight when they meet. This includes aliens swapping neighbouring cities in one step.
+	// Such a fight destroys the road instead of a city.
+	HeadOnFights bool
+	// Movement defines the order in which aliens move within a step.
+	Movement MovementOrder
+	// Engine selects discrete steps or event-driven continuous time simulation.
+	Engine Engine
+	// Delay between moves of an alien for the continuous engine, exponential with rate 1 by default.
+	Delay Delay
+	// Placement chooses starting cities of aliens, uniform by default.
+	Placement Placement
+	// Protected cities never get aliens placed into them.
+	Protected []string
+	// RebuildAfter is amount of steps after which a destroyed city is rebuilt, 0 means never.
+	RebuildAfter uint32
+	// RoadFailure is probability of every open road to be closed at the beginning of a step.
+	RoadFailure float64
+	// RoadRepairAfter is amount of steps after which a closed road is reopened, 0 means never.
+	RoadRepairAfter uint32
+	// Policy closes roads in reaction to the invasion, nil means no reaction.
+	Policy Policy
+	// Blast damages cities around destroyed ones, disabled by default.
+	Blast Blast
+	// Vitals limit lifespan and fuel of aliens keyed by species, empty species
+	// applies to all the other aliens. Aliens live and move forever by default.
+	Vitals map[string]Vitals
+	// Reproduction makes aliens spawn children, disabled by default.
+	Reproduction Reproduction
+	// Target is a city every alien travels to along the shortest route or RandomTarget
+	// for a random destination of every alien. Empty target means aliens wander randomly.
+	// The target attribute of an alien overrides it.
+	Target string
+	// Swarm makes aliens move towards or away from other aliens they sense, disabled by default.
+	Swarm Swarm
+	// Evacuation makes people flee from cities threatened by aliens, disabled by default.
+	Evacuation Evacuation
+	// Infection makes aliens occupy cities and convert their inhabitants instead of destroying them,
+	// disabled by default.
+	Infection Infection
+}
+
 type simulator struct {
 	worldMap    world.WorldMap
 	rng         *rand.Rand
 	stepsCount  uint32
 	aliensCount uint32
+	rules       Rules
+	step        uint32
+	time        float64
+	events      []Event
+	// schedule keeps actions planned for every step
+	schedule map[uint32][]Action
+	periodic []periodicAction
+	// alienWaves keeps the wave every alien has arrived with
+	alienWaves map[string]string
+	// defenders are sorted by name
+	defenders []*Defender
+	// posts keeps the city every defender starts the simulation in
+	posts map[string]string
+	// reacted is amount of events already passed to the policy
+	reacted int
+	// vitals keeps lifespan and fuel left of every alien
+	vitals map[string]*alienVitals
+	// visited keeps aliens which have arrived into undefended cities since the previous step
+	visited map[string]bool
+	// goals keeps targets of aliens travelling to them
+	goals map[string]*alienGoal
+	// openings counts rebuilt cities and reopened roads, the only changes of the map which may create new routes
+	openings uint32
+	// population keeps fate of people living in cities
+	population Population
+	// occupied keeps the last step aliens have been seen in every occupied city
+	occupied  map[string]uint32
+	recovered map[string]bool
+	epidemic  []SIR
+	// teleported keeps aliens which have moved through portals since the last head-on fight check
+	teleported map[string]bool
+	// observer is called once the world has changed at every step
+	observer func(step uint32)
+	observed int64
 }
 
 // InitSimulation creates an empty world map from given parameters.
 func InitSimulation(worldMap world.WorldMap, rng *rand.Rand, aliens uint32) simulator {
-	return simulator{worldMap: worldMap, rng: rng, stepsCount: simulatorSteps, aliensCount: aliens}
+	return simulator{worldMap: worldMap, rng: rng, stepsCount: simulatorSteps, aliensCount: aliens,
+		alienWaves: make(map[string]string), vitals: make(map[string]*alienVitals),
+		visited: make(map[string]bool), goals: make(map[string]*alienGoal),
+		occupied: make(map[string]uint32), recovered: make(map[string]bool), teleported: make(map[string]bool),
+		posts: make(map[string]string)}
+}
+
+// SetStepLimit changes maximal amount of simulation steps.
+func (sim *simulator) SetStepLimit(steps uint32) {
+	sim.stepsCount = steps
+}
+
+// SetObserver sets a function called with the world after aliens are unleashed as step 0
+// and after every following step, e.g. to draw it.
+func (sim *simulator) SetObserver(observer func(step uint32)) {
+	sim.observer = observer
+}
+
+// SetRules enables optional simulation rules.
+func (sim *simulator) SetRules(rules Rules) {
+	sim.rules = rules
 }
 
 // Simulate performs the invasion simulation. At the beginning it creates and randomly spreads
-// aliens along the world map. This follows by a fight check: if there are 2 or more aliens
+// aliens along the world map according to Rules.Placement. This follows by a fight check: if there are 2 or more aliens
 // in the same city, this city is destroyed together with all the aliens in it.
-// Then for the given amount of simulation steps (currently hardcoded to 10000)
+// Then for the amount of simulation steps set by SetStepLimit (10000 by default)
 // Simulate moves each alien in a random direction and performs a new fight check
 // AFTER all aliens have moved. This means that during the simulation step it's possible to
 // exist more than one alien in the same city without the fight if at the end of the simulation step
-// less than 2 aliens remain in the city.
-func (sim *simulator) Simulate() {
+// less than 2 aliens remain in the city. Other movement orders can be chosen with Rules.Movement,
+// in that case aliens move one by one and every arrival is checked for a fight immediately.
+// Roads longer than one step keep aliens in transit for several steps, such aliens are not
+// present in any city and fight only when they arrive. With HeadOnFights rule aliens passing
+// each other on the road fight right there and destroy the road.
+func (sim *simulator) Simulate() Result {
+	sim.step = 0
+	sim.time = 0
+	sim.events = nil
+	sim.reacted = 0
+	sim.vitals = make(map[string]*alienVitals)
+	sim.visited = make(map[string]bool)
+	sim.goals = make(map[string]*alienGoal)
+	sim.alienWaves = make(map[string]string)
+	sim.occupied = make(map[string]uint32)
+	sim.recovered = make(map[string]bool)
+	sim.teleported = make(map[string]bool)
+	sim.epidemic = nil
+	sim.observed = -1
+	sim.resetDefenders()
+	sim.resetPolicy()
+	sim.countPopulation()
 	sim.unleashAliens()
+	sim.applyScheduled(0)
+	sim.observe(0)
+	if sim.rules.Engine == ContinuousEngine {
+		return sim.simulateContinuous()
+	}
+	result := Result{Reason: StepLimitReached}
 	for i := 0; i < int(sim.stepsCount); i++ {
-		if len(sim.worldMap.GetAliens()) == 0 {
+		if len(sim.worldMap.GetAliens()) == 0 && !sim.hasPendingActions() {
 			log.Println("No more aliens to fight, stopping simulation")
+			result.Reason = NoAliensLeft
 			break
 		}
-		for _, alien := range sim.worldMap.GetAliens() {
-			sim.worldMap.MoveAlien(alien, sim.rng)
+		sim.step = uint32(i + 1)
+		sim.time = float64(sim.step)
+		sim.applyScheduled(sim.step)
+		sim.beginStep()
+		switch sim.rules.Movement {
+		case SequentialRandom, SequentialSorted:
+			sim.moveSequentially()
+		case Asynchronous:
+			sim.moveAsynchronously()
+		default:
+			sim.moveSimultaneously()
 		}
-		sim.fightAliens()
+		sim.observe(sim.step)
 	}
+	return sim.result(result.Reason)
+}
+
+func (sim *simulator) result(reason Reason) Result {
+	sim.census(sim.step)
+	sim.observe(sim.step)
+	return Result{Steps: sim.step, Time: sim.time, Reason: reason, Events: sim.events, AlienWaves: sim.alienWaves, Defenders: sim.defendersReport(),
+		Goals: sim.goalsReport(), Population: sim.populationReport(),
+		Epidemic: sim.epidemic}
+}
+
+// beginStep changes the world at the beginning of every step after scheduled actions are applied.
+func (sim *simulator) beginStep() {
+	sim.census(sim.step - 1)
+	sim.ageAliens()
+	sim.reproduce()
+	sim.failRoads()
+	sim.moveDefenders()
+	sim.evacuate()
+}
+
+// observe passes the world after the step to the observer once.
+func (sim *simulator) observe(step uint32) {
+	if sim.observer == nil || int64(step) <= sim.observed {
+		return
+	}
+	sim.observed = int64(step)
+	sim.observer(step)
 }
 
 // StopSimulation returns status of the world in the same format as input data.
+// Direction and weight of roads are preserved so the output can be used as an input again.
 func (sim *simulator) StopSimulation() string {
-	result := ""
-	result += "=== Simulation finished ===\n"
-	for name, city := range sim.worldMap.GetCities() {
-		cityOutput := fmt.Sprintf("%s ", name)
-		directions := city.GetDirections()
-		for _, dir := range directions {
-			neighbour, err := city.GetNeighbour(dir)
-			if err == nil {
-				cityOutput += fmt.Sprintf("%s=%s ", dir, neighbour)
-			} else {
-				result += err.Error() + "\n"
-			}
-		}
-		result += cityOutput + "\n"
-	}
-	return result
+	return "=== Simulation finished ===\n" + world.WriteMap(sim.worldMap)
 }
 
 func (sim *simulator) fightAliens() {
-	for city := range sim.worldMap.GetCities() {
-		sim.worldMap.DestroyCity(city)
+	for _, city := range sortedCities(sim.worldMap.GetCities()) {
+		sim.fightIn(city)
+	}
+	sim.react()
+}
+
+func sortedCities(cities map[string]*world.City) []string {
+	names := make([]string, 0, len(cities))
+	for city := range cities {
+		names = append(names, city)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (sim *simulator) record(eventType EventType, cities []string, aliens []string) {
+	sim.events = append(sim.events, Event{Step: sim.step, Time: sim.time, Type: eventType, Cities: cities, Aliens: aliens})
+}
+
+// placement returns the placement rule or the default uniform placement.
+func (sim *simulator) placement() Placement {
+	if sim.rules.Placement == nil {
+		return UniformPlacement{}
 	}
+	return sim.rules.Placement
 }
 
 func (sim *simulator) unleashAliens() {
-	for i := 0; i < int(sim.aliensCount); i++ {
+	cities, err := sim.placement().Place(sim.worldMap, sim.placementCandidates(), int(sim.aliensCount), sim.rng)
+	if err != nil {
+		log.Println(err)
+	}
+	for _, city := range cities {
 		name := sim.getRandomName()
-		city, err := sim.getRandomCity()
-		if err == nil {
-			log.Printf("Unleashing alien %s into city %s", name, city)
-			alien := world.Alien{Name: name, City: city}
-			sim.worldMap.AddAlien(&alien)
-		} else {
-			log.Println(err)
-		}
+		log.Printf("Unleashing alien %s into city %s", name, city)
+		alien := world.Alien{Name: name, City: city}
+		sim.worldMap.AddAlien(&alien)
+		sim.alienWaves[name] = initialWave
 	}
 	sim.fightAliens()
 }
 
+// getUniqueName generates random names until it finds one not used by any alien alive
+// or any alien which has taken part in the simulation.
+func (sim *simulator) getUniqueName() string {
+	for {
+		name := sim.getRandomName()
+		if _, used := sim.alienWaves[name]; !used && sim.worldMap.GetAliens()[name] == nil {
+			return name
+		}
+	}
+}
+
 func (sim *simulator) getRandomName() string {
 	const nameLength = 8
 	name := ""
@@ -96,19 +279,19 @@ func (sim *simulator) getRandomName() string {
 	return name
 }
 
-func (sim *simulator) getRandomCity() (string, error) {
-	// pretty inefficient O(n) implementation
-	// unfortunately there is no easy way to get a random element
-	// of map in Golang
-	// O(1) is possible but more complicated
-	// e.g. requires creating and maintaining
-	// a separate slice with keys
-	if len(sim.worldMap.GetCities()) == 0 {
-		return "", fmt.Errorf("there are no cities in the world")
+// placementCandidates returns sorted names of all the cities except protected ones.
+func (sim *simulator) placementCandidates() []string {
+	protected := make(map[string]bool)
+	for _, city := range sim.rules.Protected {
+		protected[city] = true
 	}
-	keys := make([]string, 0, len(sim.worldMap.GetCities()))
-	for k := range sim.worldMap.GetCities() {
-		keys = append(keys, k)
+	cities := sim.worldMap.GetCities()
+	candidates := make([]string, 0, len(cities))
+	for city := range cities {
+		if !protected[city] {
+			candidates = append(candidates, city)
+		}
 	}
-	return keys[sim.rng.Intn(len(keys))], nil
+	sort.Strings(candidates)
+	return candidates
 }
@@ -1,7 +1,10 @@
 package simulator
 
 import (
+	"io"
+	"log"
 	"math/rand"
+	"os"
 	"strings"
 	"testing"
 
@@ -66,13 +69,13 @@ func TestSimulateThreeAliensThreeCities(t *testing.T) {
 	// 3 aliens, 3 cities, nothing happens
 	ctrl := gomock.NewController(t)
 	mockWorld := mock_world.NewMockWorldMap(ctrl)
-	A := world.City{Name: "A"}
-	B := world.City{Name: "B"}
-	C := world.City{Name: "C"}
-	A.East = &B
-	B.West = &A
-	B.East = &C
-	C.West = &B
+	A := world.City{Name: "A", Roads: make(map[string]*world.Road), Topology: world.CompassTopology}
+	B := world.City{Name: "B", Roads: make(map[string]*world.Road), Topology: world.CompassTopology}
+	C := world.City{Name: "C", Roads: make(map[string]*world.Road), Topology: world.CompassTopology}
+	A.Roads["east"] = &world.Road{To: &B, Weight: 1}
+	B.Roads["west"] = &world.Road{To: &A, Weight: 1}
+	B.Roads["east"] = &world.Road{To: &C, Weight: 1}
+	C.Roads["west"] = &world.Road{To: &B, Weight: 1}
 	testCities := map[string]*world.City{
 		"A": &A,
 		"B": &B,
@@ -84,7 +87,8 @@ func TestSimulateThreeAliensThreeCities(t *testing.T) {
 		"DudeC": {Name: "DudeC", City: "C"},
 	}
 
-	mockWorld.EXPECT().GetCities().Times(3*3 + 1 + simulatorSteps).Return(testCities)
+	// 1 call to choose placement candidates + 1 call for initial fights + 1 call for every step
+	mockWorld.EXPECT().GetCities().Times(1 + 1 + simulatorSteps).Return(testCities)
 	mockWorld.EXPECT().AddAlien(gomock.Any()).Times(3)
 	mockWorld.EXPECT().GetAliens().Times(2 * simulatorSteps).Return(aliens)
 	mockWorld.EXPECT().MoveAlien(gomock.Any(), gomock.Any()).Times(3 * simulatorSteps)
@@ -96,13 +100,13 @@ func TestSimulateThreeAliensThreeCities(t *testing.T) {
 func TestStopSimulation(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockWorld := mock_world.NewMockWorldMap(ctrl)
-	A := world.City{Name: "A"}
-	B := world.City{Name: "B"}
-	C := world.City{Name: "C"}
-	A.East = &B
-	B.West = &A
-	B.East = &C
-	C.West = &B
+	A := world.City{Name: "A", Roads: make(map[string]*world.Road), Topology: world.CompassTopology}
+	B := world.City{Name: "B", Roads: make(map[string]*world.Road), Topology: world.CompassTopology}
+	C := world.City{Name: "C", Roads: make(map[string]*world.Road), Topology: world.CompassTopology}
+	A.Roads["east"] = &world.Road{To: &B, Weight: 1}
+	B.Roads["west"] = &world.Road{To: &A, Weight: 1}
+	B.Roads["east"] = &world.Road{To: &C, Weight: 1}
+	C.Roads["west"] = &world.Road{To: &B, Weight: 1}
 	testCities := map[string]*world.City{
 		"A": &A,
 		"B": &B,
@@ -116,3 +120,156 @@ func TestStopSimulation(t *testing.T) {
 	assert.Assert(t, strings.Contains(result, "B east=C west=A"))
 	assert.Assert(t, strings.Contains(result, "C west=B"))
 }
+
+func TestStopSimulationHexTopology(t *testing.T) {
+	wm := world.InitWorldMapWithTopology(world.HexTopology)
+	wm.AddCity("A", map[string]string{"northeast": "B", "southeast": "C"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	result := simulator.StopSimulation()
+	assert.Assert(t, strings.Contains(result, "A northeast=B southeast=C"))
+	assert.Assert(t, strings.Contains(result, "B southwest=A"))
+	assert.Assert(t, strings.Contains(result, "C northwest=A"))
+}
+
+func TestStopSimulationOneWayWeighted(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddRoad("River", "east", "Ferry", world.RoadOptions{OneWay: true})
+	wm.AddRoad("River", "north", "Pass", world.RoadOptions{Weight: 2.5})
+	wm.AddRoad("Pass", "east", "Ferry", world.RoadOptions{OneWay: true, Weight: 3})
+	wm.AddRoad("Ferry", "west", "Pass", world.RoadOptions{OneWay: true, Weight: 0.5})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	result := simulator.StopSimulation()
+	assert.Assert(t, strings.Contains(result, "River east>Ferry north=Pass:weight=2.5 \n"))
+	assert.Assert(t, strings.Contains(result, "Pass east>Ferry:weight=3 south=River:weight=2.5 \n"))
+	assert.Assert(t, strings.Contains(result, "Ferry west>Pass:weight=0.5 \n"))
+}
+
+func TestSimulateHeadOnFight(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddRoad("A", "east", "B", world.RoadOptions{Length: 4})
+	wm.AddAlien(&world.Alien{Name: "Westerner", City: "A"})
+	wm.AddAlien(&world.Alien{Name: "Easterner", City: "B"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetRules(Rules{HeadOnFights: true})
+	result := simulator.Simulate()
+	assert.Assert(t, result.Reason == NoAliensLeft)
+	assert.DeepEqual(t, result.Events, []Event{{Step: 2, Time: 2, Type: RoadDestroyed, Cities: []string{"A", "B"}, Aliens: []string{"Easterner", "Westerner"}}})
+	// aliens met in the middle of the road, both cities survived
+	assert.Assert(t, len(wm.GetAliens()) == 0)
+	assert.Assert(t, len(wm.GetCities()) == 2)
+	assert.Assert(t, wm.GetCities()["A"].Roads["east"] == nil)
+	assert.Assert(t, wm.GetCities()["B"].Roads["west"] == nil)
+}
+
+func TestSimulateLongRoadWithoutHeadOnFights(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddRoad("A", "east", "B", world.RoadOptions{Length: 4})
+	wm.AddAlien(&world.Alien{Name: "Westerner", City: "A"})
+	wm.AddAlien(&world.Alien{Name: "Easterner", City: "B"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	result := simulator.Simulate()
+	// aliens pass each other and never end up in the same city
+	assert.Assert(t, result.Reason == StepLimitReached)
+	assert.Assert(t, result.Steps == simulatorSteps)
+	assert.Assert(t, len(result.Events) == 0)
+	assert.Assert(t, len(wm.GetAliens()) == 2)
+	assert.Assert(t, strings.Contains(simulator.StopSimulation(), "A east=B:length=4"))
+}
+
+func TestSimulateHeadOnSwap(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddAlien(&world.Alien{Name: "Westerner", City: "A"})
+	wm.AddAlien(&world.Alien{Name: "Easterner", City: "B"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetRules(Rules{HeadOnFights: true})
+	result := simulator.Simulate()
+	// aliens swap A and B in the first step and meet on the road
+	assert.Assert(t, result.Reason == NoAliensLeft)
+	assert.DeepEqual(t, result.Events, []Event{{Step: 1, Time: 1, Type: RoadDestroyed, Cities: []string{"A", "B"}, Aliens: []string{"Easterner", "Westerner"}}})
+	assert.Assert(t, len(wm.GetCities()) == 2)
+	assert.Assert(t, wm.GetCities()["A"].Roads["east"] == nil)
+	assert.Assert(t, wm.GetCities()["B"].Roads["west"] == nil)
+}
+
+func TestSimulateSwapWithoutHeadOnFights(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddAlien(&world.Alien{Name: "Westerner", City: "A"})
+	wm.AddAlien(&world.Alien{Name: "Easterner", City: "B"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	result := simulator.Simulate()
+	// aliens swap cities every step without noticing each other
+	assert.Assert(t, result.Count(RoadDestroyed) == 0)
+	assert.Assert(t, len(wm.GetAliens()) == 2)
+}
+
+func TestSimulatePortalSwapWithHeadOnFights(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", nil)
+	wm.AddCity("B", nil)
+	wm.AddPortal("A", "gate", "B", world.PortalOptions{Chance: 1})
+	wm.AddAlien(&world.Alien{Name: "x", City: "A"})
+	wm.AddAlien(&world.Alien{Name: "y", City: "B"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(3)
+	simulator.SetRules(Rules{HeadOnFights: true})
+	result := simulator.Simulate()
+	// aliens swap cities through the portal every step without any road to meet on
+	assert.Assert(t, result.Count(RoadDestroyed) == 0)
+	assert.Assert(t, len(wm.GetAliens()) == 2)
+}
+
+func TestSimulateCityDestroyedEvent(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("Uglich", map[string]string{})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 2)
+	result := simulator.Simulate()
+	assert.Assert(t, result.Reason == NoAliensLeft)
+	assert.Assert(t, result.Steps == 0)
+	assert.Assert(t, len(result.Events) == 1)
+	assert.Assert(t, result.Events[0].Type == CityDestroyed)
+	assert.DeepEqual(t, result.Events[0].Cities, []string{"Uglich"})
+	assert.Assert(t, len(result.Events[0].Aliens) == 2)
+}
+
+func TestSimulateObserver(t *testing.T) {
+	for _, engine := range []Engine{DiscreteEngine, ContinuousEngine} {
+		wm := world.InitWorldMap()
+		wm.AddCity("A", map[string]string{"east": "B"})
+		wm.AddAlien(&world.Alien{Name: "X", City: "A"})
+		simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+		simulator.SetStepLimit(3)
+		simulator.SetRules(Rules{Engine: engine, Delay: FixedDelay{Value: 1}})
+		steps := make([]uint32, 0)
+		simulator.SetObserver(func(step uint32) {
+			steps = append(steps, step)
+		})
+		simulator.Simulate()
+		assert.DeepEqual(t, steps, []uint32{0, 1, 2, 3})
+	}
+}
+
+func TestStopSimulationMetadata(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("Vienna", map[string]string{"west": "Linz"})
+	wm.SetMetadata("Vienna", "population", "1900000")
+	wm.SetMetadata("Vienna", "capital", "yes")
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	result := simulator.StopSimulation()
+	assert.Assert(t, strings.Contains(result, "Vienna west=Linz @capital=yes @population=1900000 \n"))
+}
+
+func BenchmarkSimulateTorus(b *testing.B) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		wm, err := world.GenerateGrid(world.Grid{Width: 30, Height: 30, WrapX: true, WrapY: true}, rand.New(rand.NewSource(int64(i))))
+		assert.NilError(b, err)
+		simulator := InitSimulation(wm, rand.New(rand.NewSource(int64(i))), 100)
+		simulator.SetStepLimit(1000)
+		b.StartTimer()
+		simulator.Simulate()
+	}
+}
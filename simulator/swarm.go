@@ -0,0 +1,120 @@
+package simulator
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/luckychess/invasion/world"
+)
+
+// SwarmMode defines how aliens react to other aliens they sense.
+type SwarmMode int
+
+const (
+	// NoSwarm makes aliens ignore each other and walk randomly.
+	NoSwarm SwarmMode = iota
+	// Attraction makes aliens move towards other aliens, so cities are destroyed faster.
+	Attraction
+	// Repulsion makes aliens move away from other aliens, so they survive longer.
+	Repulsion
+)
+
+// Swarm makes aliens sense other aliens in cities within Radius roads from their neighbours.
+// An alien moves to the neighbour with most aliens around it for attraction and least aliens
+// for repulsion, closer aliens count more. Aliens which sense nobody walk randomly.
+type Swarm struct {
+	Mode SwarmMode
+	// Radius is amount of roads from a neighbour city where aliens are sensed, 0 means only the neighbour itself.
+	Radius uint32
+}
+
+// ParseSwarm creates a swarm from its description: attract[:<radius>] or repel[:<radius>].
+func ParseSwarm(spec string) (Swarm, error) {
+	parts := strings.Split(spec, ":")
+	swarm := Swarm{}
+	switch parts[0] {
+	case "attract":
+		swarm.Mode = Attraction
+	case "repel":
+		swarm.Mode = Repulsion
+	default:
+		return swarm, fmt.Errorf("unknown swarm mode %s", parts[0])
+	}
+	if len(parts) > 2 {
+		return swarm, fmt.Errorf("wrong swarm %s, expected attract[:<radius>] or repel[:<radius>]", spec)
+	}
+	if len(parts) == 2 {
+		radius, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			return swarm, fmt.Errorf("swarm radius should be a non-negative integer but got %s", parts[1])
+		}
+		swarm.Radius = uint32(radius)
+	}
+	return swarm, nil
+}
+
+// swarm moves the alien according to the swarm rule.
+func (sim *simulator) swarm(alien *world.Alien) {
+	city := sim.worldMap.GetCities()[alien.City]
+	if alien.Transit != nil || city == nil {
+		sim.moveRandomly(alien)
+		return
+	}
+	directions := city.GetDirections()
+	best := make([]string, 0, len(directions))
+	bestScore, sensed := 0, false
+	for _, direction := range directions {
+		score := sim.sense(city.Roads[direction].To, alien.Name)
+		sensed = sensed || score > 0
+		if sim.rules.Swarm.Mode == Repulsion {
+			score = -score
+		}
+		if len(best) == 0 || score > bestScore {
+			best, bestScore = []string{direction}, score
+		} else if score == bestScore {
+			best = append(best, direction)
+		}
+	}
+	if !sensed {
+		sim.moveRandomly(alien)
+		return
+	}
+	if err := sim.worldMap.MoveAlienAlong(alien, best[sim.rng.Intn(len(best))]); err != nil {
+		log.Println(err)
+	}
+}
+
+// sense scores aliens other than the given one within the swarm radius from the city.
+// Every alien scores the amount of roads left to the edge of the radius plus one.
+func (sim *simulator) sense(from *world.City, self string) int {
+	radius := int(sim.rules.Swarm.Radius)
+	score := 0
+	distances := map[string]int{from.Name: 0}
+	queue := []*world.City{from}
+	for len(queue) > 0 {
+		city := queue[0]
+		queue = queue[1:]
+		distance := distances[city.Name]
+		for alien := range city.Aliens {
+			if alien != self {
+				score += radius - distance + 1
+			}
+		}
+		if distance == radius {
+			continue
+		}
+		for _, direction := range city.GetDirections() {
+			neighbour, err := city.GetNeighbour(direction)
+			if err != nil {
+				continue
+			}
+			if _, ok := distances[neighbour]; !ok {
+				distances[neighbour] = distance + 1
+				queue = append(queue, city.Roads[direction].To)
+			}
+		}
+	}
+	return score
+}
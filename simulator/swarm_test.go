@@ -0,0 +1,49 @@
+package simulator
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/luckychess/invasion/world"
+	"gotest.tools/v3/assert"
+)
+
+func TestSwarm(t *testing.T) {
+	for _, test := range []struct {
+		mode SwarmMode
+		city string
+	}{{Attraction, "C"}, {Repulsion, "A"}} {
+		wm := world.InitWorldMap()
+		wm.AddRoad("A", "east", "B", world.RoadOptions{})
+		wm.AddRoad("B", "east", "C", world.RoadOptions{})
+		wm.AddRoad("C", "east", "D", world.RoadOptions{})
+		wm.AddAlien(&world.Alien{Name: "X", City: "B"})
+		wm.AddAlien(&world.Alien{Name: "Y", City: "D"})
+		simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+		simulator.SetRules(Rules{Swarm: Swarm{Mode: test.mode, Radius: 1}})
+		assert.Assert(t, simulator.sense(wm.GetCities()["C"], "X") == 1)
+		assert.Assert(t, simulator.sense(wm.GetCities()["D"], "X") == 2)
+		assert.Assert(t, simulator.sense(wm.GetCities()["A"], "X") == 0)
+		simulator.moveAlien(wm.GetAliens()["X"])
+		assert.Assert(t, wm.GetAliens()["X"].City == test.city, test.mode)
+	}
+}
+
+func TestParseSwarm(t *testing.T) {
+	swarm, err := ParseSwarm("attract:2")
+	assert.NilError(t, err)
+	assert.Assert(t, swarm == Swarm{Mode: Attraction, Radius: 2})
+	swarm, err = ParseSwarm("repel")
+	assert.NilError(t, err)
+	assert.Assert(t, swarm == Swarm{Mode: Repulsion})
+	_, err = ParseSwarm("flock:1")
+	assert.Error(t, err, "unknown swarm mode flock")
+	_, err = ParseSwarm("repel:far")
+	assert.Error(t, err, "swarm radius should be a non-negative integer but got far")
+}
+
+func TestDestructionRate(t *testing.T) {
+	result := Result{Steps: 4, Events: []Event{{Type: CityDestroyed}, {Type: RoadDestroyed}, {Type: CityDestroyed}}}
+	assert.Assert(t, result.DestructionRate() == 0.5)
+	assert.Assert(t, (&Result{}).DestructionRate() == 0)
+}
@@ -0,0 +1,127 @@
+package simulator
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/luckychess/invasion/world"
+)
+
+// Vitals limit life of aliens. Zero values mean no limit.
+type Vitals struct {
+	// Lifespan is amount of steps an alien lives after its arrival, then it dies of exhaustion
+	// without destroying its city.
+	Lifespan uint32
+	// Fuel is amount of moves an alien is able to make, then it becomes stranded
+	// in its city or on the road it travels.
+	Fuel uint32
+}
+
+// alienVitals keeps what is left of alien's lifespan and fuel.
+type alienVitals struct {
+	age      uint32
+	lifespan uint32
+	fuel     uint32
+	// unlimited fuel is not tracked
+	limited bool
+}
+
+// vitalsOf returns vitals of the alien initializing them from its species on the first call.
+// Rules.Vitals keyed by empty species are used for aliens without own species entry,
+// lifespan and fuel attributes of the alien override the species values.
+func (sim *simulator) vitalsOf(alien *world.Alien) *alienVitals {
+	if v, ok := sim.vitals[alien.Name]; ok {
+		return v
+	}
+	species, ok := sim.rules.Vitals[alien.Attributes["species"]]
+	if !ok {
+		species = sim.rules.Vitals[""]
+	}
+	v := &alienVitals{lifespan: species.Lifespan, fuel: species.Fuel}
+	if err := overrideVital(&v.lifespan, alien, "lifespan"); err != nil {
+		log.Println(err)
+	}
+	if err := overrideVital(&v.fuel, alien, "fuel"); err != nil {
+		log.Println(err)
+	}
+	v.limited = v.fuel > 0
+	sim.vitals[alien.Name] = v
+	return v
+}
+
+func overrideVital(value *uint32, alien *world.Alien, key string) error {
+	attribute, ok := alien.Attributes[key]
+	if !ok {
+		return nil
+	}
+	number, err := strconv.ParseUint(attribute, 10, 32)
+	if err != nil {
+		return fmt.Errorf("alien %s has non-numeric %s: %s", alien.Name, key, attribute)
+	}
+	*value = uint32(number)
+	return nil
+}
+
+// moveAlien moves the alien if it has fuel left. Aliens with goals travel towards their targets.
+// Only moves which change position of the alien consume fuel. Arrivals into undefended cities
+// are remembered for reproduction.
+func (sim *simulator) moveAlien(alien *world.Alien) {
+	if len(sim.rules.Vitals) == 0 && !sim.rules.Reproduction.OnVisit {
+		sim.travel(alien)
+		sim.arrive(alien)
+		return
+	}
+	v := sim.vitalsOf(alien)
+	if v.limited && v.fuel == 0 {
+		return
+	}
+	before := positionOf(alien)
+	sim.travel(alien)
+	sim.arrive(alien)
+	if positionOf(alien) == before {
+		return
+	}
+	if sim.rules.Reproduction.OnVisit && alien.City != "" && !sim.isDefended(alien.City) {
+		sim.visited[alien.Name] = true
+	}
+	if !v.limited {
+		return
+	}
+	v.fuel--
+	if v.fuel == 0 {
+		where := alien.City
+		if alien.Transit != nil {
+			where = "the road from " + alien.Transit.From + " to " + alien.Transit.To
+		}
+		log.Printf("Alien %s has run out of fuel in %s", alien.Name, where)
+		sim.record(AlienStranded, positionCities(alien), []string{alien.Name})
+	}
+}
+
+// ageAliens makes every alien one step older and removes the ones which have lived their lifespan.
+func (sim *simulator) ageAliens() {
+	if len(sim.rules.Vitals) == 0 && sim.rules.Reproduction.Every == 0 {
+		return
+	}
+	aliens := sim.worldMap.GetAliens()
+	for _, name := range sortedNames(aliens) {
+		alien := aliens[name]
+		v := sim.vitalsOf(alien)
+		v.age++
+		if v.lifespan > 0 && v.age > v.lifespan {
+			cities := positionCities(alien)
+			sim.worldMap.RemoveAlien(name)
+			log.Printf("Alien %s has died of exhaustion", name)
+			sim.record(AlienExhausted, cities, []string{name})
+		}
+	}
+}
+
+// positionCities returns the city of the alien or both ends of the road it travels.
+func positionCities(alien *world.Alien) []string {
+	if alien.Transit != nil {
+		return uniqueSorted([]string{alien.Transit.From, alien.Transit.To})
+	}
+	return []string{alien.City}
+}
@@ -0,0 +1,54 @@
+package simulator
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/luckychess/invasion/world"
+	"gotest.tools/v3/assert"
+)
+
+func TestAlienLifespan(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddAlien(&world.Alien{Name: "Grey", City: "A", Attributes: map[string]string{"species": "grey"}})
+	wm.AddAlien(&world.Alien{Name: "Other", City: "B"})
+	wm.AddAlien(&world.Alien{Name: "Tough", City: "B", Attributes: map[string]string{"species": "grey", "lifespan": "100"}})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(10)
+	simulator.SetRules(Rules{Movement: SequentialSorted, Vitals: map[string]Vitals{"grey": {Lifespan: 2}, "": {Lifespan: 1}}})
+	// B is destroyed before the first step, the grey alien dies of exhaustion at the beginning of step 3
+	result := simulator.Simulate()
+	assert.Assert(t, result.Reason == NoAliensLeft)
+	assert.Assert(t, result.Count(CityDestroyed) == 1)
+	assert.Assert(t, result.Count(AlienExhausted) == 1)
+	event := result.Events[len(result.Events)-1]
+	assert.Assert(t, event.Step == 3 && event.Type == AlienExhausted)
+	assert.DeepEqual(t, event.Aliens, []string{"Grey"})
+	// exhaustion doesn't destroy the city
+	assert.Assert(t, wm.GetCities()["A"] != nil)
+}
+
+func TestAlienFuel(t *testing.T) {
+	wm := world.InitWorldMap()
+	wm.AddRoad("A", "east", "B", world.RoadOptions{Length: 3})
+	wm.AddAlien(&world.Alien{Name: "X", City: "A"})
+	simulator := InitSimulation(wm, rand.New(rand.NewSource(0)), 0)
+	simulator.SetStepLimit(10)
+	simulator.SetRules(Rules{Vitals: map[string]Vitals{"": {Fuel: 2}}})
+	result := simulator.Simulate()
+	// the alien runs out of fuel in the middle of the long road and remains stranded there
+	assert.Assert(t, result.Reason == StepLimitReached)
+	assert.DeepEqual(t, result.Events, []Event{{Step: 2, Time: 2, Type: AlienStranded, Cities: []string{"A", "B"}, Aliens: []string{"X"}}})
+	alien := wm.GetAliens()["X"]
+	assert.Assert(t, alien.Transit != nil && alien.Transit.Remaining == 1)
+}
+
+func TestAlienVitalsAttributes(t *testing.T) {
+	simulator := InitSimulation(world.InitWorldMap(), rand.New(rand.NewSource(0)), 0)
+	simulator.SetRules(Rules{Vitals: map[string]Vitals{"grey": {Lifespan: 5, Fuel: 7}}})
+	v := simulator.vitalsOf(&world.Alien{Name: "X", Attributes: map[string]string{"species": "grey", "fuel": "3"}})
+	assert.Assert(t, v.lifespan == 5 && v.fuel == 3 && v.limited)
+	v = simulator.vitalsOf(&world.Alien{Name: "Y", Attributes: map[string]string{"lifespan": "many"}})
+	assert.Assert(t, v.lifespan == 0 && !v.limited)
+}
@@ -0,0 +1,132 @@
+package world
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseASCIIMap creates a world map from a drawing of a grid. Cities are drawn as names or labels
+// without spaces, - connects a city with the next city on its east and | connects a city with
+// the city below it on its south, so the topology must have these directions. Lines of the
+// label=Name format after the drawing give full names to labels, e.g.
+//
+//	C -- B
+//	|
+//	F    L
+//	C=Cologne
+//	B=Berlin
+func ParseASCIIMap(lines []string, topology *Topology) (WorldMap, error) {
+	drawing := make([][]rune, 0, len(lines))
+	legend := make(map[string]string)
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \r")
+		if strings.Contains(line, "=") {
+			label := strings.SplitN(strings.TrimSpace(line), "=", 2)
+			if label[0] == "" || label[1] == "" || strings.ContainsAny(label[0]+label[1], " -|") {
+				return nil, fmt.Errorf("line %d: expected label=Name but got %s", i+1, line)
+			}
+			legend[label[0]] = label[1]
+			continue
+		}
+		if len(legend) > 0 && line != "" {
+			return nil, fmt.Errorf("line %d: the legend should follow the drawing", i+1)
+		}
+		if strings.ContainsAny(line, "\t>:~@#") {
+			return nil, fmt.Errorf("line %d: unexpected character in %s, only city names, spaces, - and | are allowed", i+1, line)
+		}
+		drawing = append(drawing, []rune(line))
+	}
+	worldMap := InitWorldMapWithTopology(topology)
+	// cells keeps the city name drawn at every position
+	cells := make([][]string, len(drawing))
+	drawn := make(map[string]bool)
+	addCity := func(label string, line int) (string, error) {
+		name := label
+		if fullName, ok := legend[label]; ok {
+			name = fullName
+		}
+		if drawn[name] {
+			return "", fmt.Errorf("line %d: city %s is drawn twice", line+1, name)
+		}
+		drawn[name] = true
+		return name, worldMap.AddCity(name, nil)
+	}
+	for row, line := range drawing {
+		cells[row] = make([]string, len(line))
+		west, road := "", false
+		for column := 0; column < len(line); column++ {
+			switch line[column] {
+			case ' ':
+			case '-':
+				if west == "" {
+					return nil, fmt.Errorf("line %d: road at column %d has no city on the west", row+1, column+1)
+				}
+				road = true
+			case '|':
+				if road {
+					return nil, fmt.Errorf("line %d: roads cross at column %d", row+1, column+1)
+				}
+				west = ""
+			default:
+				start := column
+				for column < len(line) && !strings.ContainsRune(" -|", line[column]) {
+					column++
+				}
+				name, err := addCity(string(line[start:column]), row)
+				if err != nil {
+					return nil, err
+				}
+				for i := start; i < column; i++ {
+					cells[row][i] = name
+				}
+				column--
+				if road {
+					if err := worldMap.AddRoad(west, "east", name, RoadOptions{}); err != nil {
+						return nil, err
+					}
+				}
+				west, road = name, false
+			}
+		}
+		if road {
+			return nil, fmt.Errorf("line %d: road has no city on the east", row+1)
+		}
+	}
+	cellAt := func(row int, column int) string {
+		if row < 0 || row >= len(cells) || column >= len(cells[row]) {
+			return ""
+		}
+		return cells[row][column]
+	}
+	isRoad := func(row int, column int) bool {
+		return row >= 0 && row < len(drawing) && column < len(drawing[row]) && drawing[row][column] == '|'
+	}
+	for row, line := range drawing {
+		for column := range line {
+			if !isRoad(row, column) || isRoad(row-1, column) {
+				continue
+			}
+			north := cellAt(row-1, column)
+			if north == "" {
+				return nil, fmt.Errorf("line %d: road at column %d has no city on the north", row+1, column+1)
+			}
+			end := row
+			for isRoad(end, column) {
+				end++
+			}
+			south := cellAt(end, column)
+			if south == "" {
+				return nil, fmt.Errorf("line %d: road at column %d has no city on the south", row+1, column+1)
+			}
+			if err := worldMap.AddRoad(north, "south", south, RoadOptions{}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for label, name := range legend {
+		if !drawn[name] {
+			return nil, fmt.Errorf("label %s of the legend is not drawn", label)
+		}
+	}
+	return worldMap, nil
+}
@@ -0,0 +1,47 @@
+package world
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseASCIIMap(t *testing.T) {
+	wm, err := ParseASCIIMap([]string{
+		"Foo--Bar  Baz",
+		" |    |",
+		" |   Qu_ux - Q",
+		" |",
+		"Moon",
+	}, CompassTopology)
+	assert.NilError(t, err)
+	assert.Equal(t, WriteMap(wm), "Bar west=Foo south=Qu_ux \n"+
+		"Baz \n"+
+		"Foo east=Bar south=Moon \n"+
+		"Moon north=Foo \n"+
+		"Q west=Qu_ux \n"+
+		"Qu_ux east=Q north=Bar \n")
+}
+
+func TestParseASCIIMapErrors(t *testing.T) {
+	for _, test := range []struct {
+		lines []string
+		err   string
+	}{
+		{[]string{"- A"}, "line 1: road at column 1 has no city on the west"},
+		{[]string{"A -"}, "line 1: road has no city on the east"},
+		{[]string{"A -|- B"}, "line 1: roads cross at column 4"},
+		{[]string{"A", " |"}, "line 2: road at column 2 has no city on the north"},
+		{[]string{"A", "|"}, "line 2: road at column 1 has no city on the south"},
+		{[]string{"A - A"}, "line 1: city A is drawn twice"},
+		{[]string{"A\tB"}, "line 1: unexpected character in A\tB, only city names, spaces, - and | are allowed"},
+		{[]string{"A", "A=Alpha", "B"}, "line 3: the legend should follow the drawing"},
+		{[]string{"A", "=Alpha"}, "line 2: expected label=Name but got =Alpha"},
+		{[]string{"A", "B=Beta"}, "label B of the legend is not drawn"},
+	} {
+		_, err := ParseASCIIMap(test.lines, CompassTopology)
+		assert.Error(t, err, test.err)
+	}
+	_, err := ParseASCIIMap([]string{"A", "|", "B"}, HexTopology)
+	assert.Error(t, err, "wrong direction south")
+}
@@ -4,40 +4,111 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // Alien structure contains name and current city name of alien.
+// While the alien travels along a long road its city is empty and Transit is set.
 type Alien struct {
-	Name string
-	City string
+	Name    string
+	City    string
+	Transit *Transit
+	// Attributes keeps arbitrary properties of the alien, e.g. its species.
+	Attributes map[string]string
 }
 
-// City contains name of the city and pointers to cities in other directions.
+// Transit describes position of an alien travelling along a road.
+type Transit struct {
+	From      string
+	Direction string
+	To        string
+	Length    uint32
+	// Remaining is amount of steps left to reach the destination.
+	Remaining uint32
+}
+
+// Progress returns amount of steps already made along the road.
+func (t *Transit) Progress() uint32 {
+	return t.Length - t.Remaining
+}
+
+// Road is a one-way connection from a city to its neighbour.
+// Two-way roads are represented by a pair of roads in opposite directions.
+type Road struct {
+	To *City
+	// Weight biases the choice of the road when an alien moves.
+	Weight float64
+	// Length is amount of steps required to travel along the road.
+	Length uint32
+}
+
+// RoadOptions describes optional properties of a road added to the world.
+type RoadOptions struct {
+	// Weight of the road, zero means default weight 1.
+	Weight float64
+	// Length of the road in steps, zero means default length 1.
+	Length uint32
+	// OneWay roads don't create a reciprocal road back.
+	OneWay bool
+	// Closed roads exist but can't be used until they are reopened.
+	Closed bool
+}
+
+// Portal is a one-way link to a city which doesn't follow any direction of the topology.
+// Two-way portals are represented by a pair of portals with the same name.
+type Portal struct {
+	To *City
+	// Chance is probability of an alien in the city to traverse the portal when it moves.
+	Chance float64
+}
+
+// PortalOptions describes optional properties of a portal added to the world.
+type PortalOptions struct {
+	// Chance of the portal to be traversed, zero means default chance 0.5.
+	Chance float64
+	// OneWay portals don't create a reciprocal portal back.
+	OneWay bool
+}
+
+// defaultPortalChance is used for portals without explicit chance.
+const defaultPortalChance = 0.5
+
+// City contains name of the city and roads to neighbour cities keyed by direction.
 // It also contains all aliens currently in the city.
 type City struct {
-	Name   string
-	East   *City
-	North  *City
-	West   *City
-	South  *City
-	Aliens map[string]bool
+	Name  string
+	Roads map[string]*Road
+	// Closed keeps roads which temporarily can't be used keyed by direction.
+	Closed map[string]*Road
+	// Portals keeps links to distant cities keyed by portal name.
+	Portals map[string]*Portal
+	Aliens  map[string]bool
+	// Metadata keeps arbitrary attributes of the city given in the map, e.g. population.
+	Metadata map[string]string
+	// Topology defines the order of directions. If it's nil, directions
+	// are sorted alphabetically.
+	Topology *Topology
 }
 
 // GetDirections is a helper function which returns a slice of
 // possible directions to go from the city.
 func (c *City) GetDirections() []string {
-	directions := make([]string, 0)
-	if c.East != nil {
-		directions = append(directions, "east")
-	}
-	if c.North != nil {
-		directions = append(directions, "north")
-	}
-	if c.West != nil {
-		directions = append(directions, "west")
+	directions := make([]string, 0, len(c.Roads))
+	if c.Topology == nil {
+		for direction, road := range c.Roads {
+			if road != nil {
+				directions = append(directions, direction)
+			}
+		}
+		sort.Strings(directions)
+		return directions
 	}
-	if c.South != nil {
-		directions = append(directions, "south")
+	for _, direction := range c.Topology.Directions {
+		if c.Roads[direction] != nil {
+			directions = append(directions, direction)
+		}
 	}
 	return directions
 }
@@ -45,58 +116,148 @@ func (c *City) GetDirections() []string {
 // GetNeighbour returns name of the city in given direction or error
 // if no city in this direction exists.
 func (c *City) GetNeighbour(direction string) (string, error) {
-	switch direction {
-	case "east":
-		if c.East != nil {
-			return c.East.Name, nil
-		}
-	case "north":
-		if c.North != nil {
-			return c.North.Name, nil
-		}
-	case "west":
-		if c.West != nil {
-			return c.West.Name, nil
-		}
-	case "south":
-		if c.South != nil {
-			return c.South.Name, nil
-		}
-	default:
+	if c.Topology != nil && !c.Topology.Has(direction) {
 		return "", fmt.Errorf("wrong direction %s", direction)
 	}
+	if road := c.Roads[direction]; road != nil {
+		return road.To.Name, nil
+	}
 	return "", fmt.Errorf("no cities in %s direction", direction)
 }
 
+// GetMetadataFloat returns numeric metadata value or the fallback value if it's missing.
+func (c *City) GetMetadataFloat(key string, fallback float64) (float64, error) {
+	value, ok := c.Metadata[key]
+	if !ok {
+		return fallback, nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fallback, fmt.Errorf("city %s has non-numeric %s: %s", c.Name, key, value)
+	}
+	return number, nil
+}
+
+// IsTwoWay checks whether the road in given direction has a reciprocal road
+// back to this city with the same weight and length. Closed roads are checked
+// against closed roads back.
+func (c *City) IsTwoWay(direction string) bool {
+	closed := c.Roads[direction] == nil
+	road := c.roads(closed)[direction]
+	if road == nil || c.Topology == nil {
+		return false
+	}
+	opposite, _ := c.Topology.Opposite(direction)
+	back := road.To.roads(closed)[opposite]
+	return back != nil && back.To == c && back.Weight == road.Weight && back.Length == road.Length
+}
+
+// PortalNames returns sorted names of portals of the city.
+func (c *City) PortalNames() []string {
+	names := make([]string, 0, len(c.Portals))
+	for name := range c.Portals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsTwoWayPortal checks whether the portal with given name has a portal with the same name
+// back to this city with the same chance.
+func (c *City) IsTwoWayPortal(name string) bool {
+	portal := c.Portals[name]
+	if portal == nil {
+		return false
+	}
+	back := portal.To.Portals[name]
+	return back != nil && back.To == c && back.Chance == portal.Chance
+}
+
+// roads returns either open or closed roads of the city.
+func (c *City) roads(closed bool) map[string]*Road {
+	if closed {
+		return c.Closed
+	}
+	return c.Roads
+}
+
 // WorldMap interface describes actions available for the world.
 type WorldMap interface {
 	// GetCities returns all cities in the world.
 	GetCities() map[string]*City
 	// GetCities returns all aliens in the world.
 	GetAliens() map[string]*Alien
+	// GetTopology returns directions vocabulary of the world.
+	GetTopology() *Topology
+	// GetRuins returns destroyed cities which can be rebuilt.
+	GetRuins() map[string]*Ruin
 	// AddCity adds a new city to the world and also creates or updates information
-	// about neighbours of the given city.
-	AddCity(name string, east string, north string, west string, south string)
+	// about neighbours of the given city. Roads are given as direction to city name pairs
+	// and are always two-way with default weight.
+	AddCity(name string, roads map[string]string) error
+	// AddRoad adds a road from one city to another in given direction creating the cities
+	// if they don't exist yet. Unless the road is one-way, a road back is created as well.
+	AddRoad(from string, direction string, to string, options RoadOptions) error
+	// AddPortal adds a named portal from one city to another creating the cities if they don't exist yet.
+	// Unless the portal is one-way, a portal back with the same name is created as well.
+	AddPortal(from string, name string, to string, options PortalOptions) error
+	// SetMetadata sets an attribute of existing city.
+	SetMetadata(city string, key string, value string) error
 	// AddAlien adds alien into the world.
 	AddAlien(alien *Alien) error
+	// RemoveAlien removes alien from the world without any fight.
+	RemoveAlien(name string)
 	// MoveAlien moves given alien in a random direction
-	// if there are directions to move.
-	MoveAlien(alien *Alien, rng *rand.Rand)
-	// Destroy city deletes city and all aliens in it if there are 2 or
-	// more aliens in the city.
-	DestroyCity(cityToDestroy string)
+	// if there are directions to move. Directions are chosen proportionally to road weights.
+	// Portals of the city are tried first in order of their names, every portal is traversed
+	// with its chance.
+	// Roads longer than one step put the alien in transit, further calls move it along the road.
+	// It returns true if the alien has teleported.
+	MoveAlien(alien *Alien, rng *rand.Rand) bool
+	// MoveAlienAlong moves given alien along the open road in given direction from its city.
+	// Aliens in transit ignore the direction and continue their travel.
+	MoveAlienAlong(alien *Alien, direction string) error
+	// DestroyRoad removes the road in given direction from the city together with
+	// the road back if it exists.
+	DestroyRoad(from string, direction string) error
+	// CloseRoad makes the road in given direction and the road back unusable until they are reopened.
+	// Aliens already travelling the road are not affected.
+	CloseRoad(from string, direction string) error
+	// ReopenRoad restores the closed road in given direction together with the closed road back.
+	ReopenRoad(from string, direction string) error
+	// Destroy city deletes city, all aliens in it and portals leading to it if there are 2 or
+	// more aliens in the city. It returns sorted names of killed aliens
+	// or nil if the city hasn't been destroyed.
+	DestroyCity(cityToDestroy string) []string
+	// RuinCity deletes city and all aliens in it regardless of their amount.
+	// It returns sorted names of killed aliens.
+	RuinCity(name string) []string
+	// RebuildCity restores a destroyed city with its metadata, roads and portals to the cities
+	// which exist now. Roads and portals to cities which are destroyed as well are restored
+	// when these neighbours are rebuilt.
+	RebuildCity(name string) error
 }
 
 type worldMapImpl struct {
-	Cities map[string]*City
-	Aliens map[string]*Alien
+	Cities   map[string]*City
+	Aliens   map[string]*Alien
+	Ruins    map[string]*Ruin
+	topology *Topology
 }
 
-// InitWorldMap creates an empty world map with no cities and aliens.
+// InitWorldMap creates an empty world map with no cities and aliens
+// which uses classic compass directions.
 func InitWorldMap() WorldMap {
-	worldMap := worldMapImpl{}
+	return InitWorldMapWithTopology(CompassTopology)
+}
+
+// InitWorldMapWithTopology creates an empty world map with no cities and aliens
+// which uses given directions vocabulary.
+func InitWorldMapWithTopology(topology *Topology) WorldMap {
+	worldMap := worldMapImpl{topology: topology}
 	worldMap.Cities = make(map[string]*City)
 	worldMap.Aliens = make(map[string]*Alien)
+	worldMap.Ruins = make(map[string]*Ruin)
 	return &worldMap
 }
 
@@ -108,49 +269,142 @@ func (m *worldMapImpl) GetAliens() map[string]*Alien {
 	return m.Aliens
 }
 
-func (m *worldMapImpl) AddCity(name string, east string, north string, west string, south string) {
-	city := &City{Name: name, Aliens: make(map[string]bool)}
-	if m.Cities[name] != nil {
-		city = m.Cities[name]
+func (m *worldMapImpl) GetTopology() *Topology {
+	return m.topology
+}
+
+func (m *worldMapImpl) AddCity(name string, roads map[string]string) error {
+	// check all directions first to not leave the city half-linked
+	for direction := range roads {
+		if !m.topology.Has(direction) {
+			return fmt.Errorf("wrong direction %s", direction)
+		}
 	}
-	if east != "" {
-		eastCity := m.Cities[east]
-		if eastCity == nil {
-			eastCity = &City{Name: east, East: nil, North: nil, West: city, South: nil, Aliens: make(map[string]bool)}
+	m.getOrCreateCity(name)
+	for direction, neighbourName := range roads {
+		if neighbourName == "" {
+			continue
 		}
-		city.East = eastCity
-		eastCity.West = city
-		m.Cities[east] = eastCity
-	}
-	if north != "" {
-		northCity := m.Cities[north]
-		if northCity == nil {
-			northCity = &City{Name: north, East: nil, North: nil, West: nil, South: city, Aliens: make(map[string]bool)}
+		if err := m.AddRoad(name, direction, neighbourName, RoadOptions{}); err != nil {
+			return err
 		}
-		city.North = northCity
-		northCity.South = city
-		m.Cities[north] = northCity
-	}
-	if west != "" {
-		westCity := m.Cities[west]
-		if westCity == nil {
-			westCity = &City{Name: west, East: city, North: nil, West: nil, South: nil, Aliens: make(map[string]bool)}
+	}
+	return nil
+}
+
+func (m *worldMapImpl) AddRoad(from string, direction string, to string, options RoadOptions) error {
+	opposite, ok := m.topology.Opposite(direction)
+	if !ok {
+		return fmt.Errorf("wrong direction %s", direction)
+	}
+	if options.Weight < 0 {
+		return fmt.Errorf("road weight should be positive but got %g", options.Weight)
+	}
+	if options.Weight == 0 {
+		options.Weight = 1
+	}
+	if options.Length == 0 {
+		options.Length = 1
+	}
+	city := m.getOrCreateCity(from)
+	neighbour := m.getOrCreateCity(to)
+	city.setRoad(direction, &Road{To: neighbour, Weight: options.Weight, Length: options.Length}, options.Closed)
+	if !options.OneWay {
+		neighbour.setRoad(opposite, &Road{To: city, Weight: options.Weight, Length: options.Length}, options.Closed)
+	}
+	return nil
+}
+
+func (m *worldMapImpl) AddPortal(from string, name string, to string, options PortalOptions) error {
+	if name == "" {
+		return fmt.Errorf("portal from %s to %s has no name", from, to)
+	}
+	if options.Chance < 0 || options.Chance > 1 {
+		return fmt.Errorf("portal chance should be between 0 and 1 but got %g", options.Chance)
+	}
+	if options.Chance == 0 {
+		options.Chance = defaultPortalChance
+	}
+	city := m.getOrCreateCity(from)
+	neighbour := m.getOrCreateCity(to)
+	city.Portals[name] = &Portal{To: neighbour, Chance: options.Chance}
+	if !options.OneWay {
+		neighbour.Portals[name] = &Portal{To: city, Chance: options.Chance}
+	}
+	return nil
+}
+
+// setRoad puts the road in given direction replacing any open or closed road there.
+func (c *City) setRoad(direction string, road *Road, closed bool) {
+	delete(c.Roads, direction)
+	delete(c.Closed, direction)
+	if closed {
+		c.Closed[direction] = road
+	} else {
+		c.Roads[direction] = road
+	}
+}
+
+func (m *worldMapImpl) CloseRoad(from string, direction string) error {
+	city := m.Cities[from]
+	if city == nil || city.Roads[direction] == nil {
+		return fmt.Errorf("no open road from %s in %s direction", from, direction)
+	}
+	m.switchRoad(city, direction, true)
+	return nil
+}
+
+func (m *worldMapImpl) ReopenRoad(from string, direction string) error {
+	city := m.Cities[from]
+	if city == nil || city.Closed[direction] == nil {
+		return fmt.Errorf("no closed road from %s in %s direction", from, direction)
+	}
+	m.switchRoad(city, direction, false)
+	return nil
+}
+
+// switchRoad moves the road in given direction and the road back between open and closed roads.
+func (m *worldMapImpl) switchRoad(city *City, direction string, closed bool) {
+	road := city.roads(!closed)[direction]
+	neighbour := road.To
+	city.setRoad(direction, road, closed)
+	if opposite, ok := m.topology.Opposite(direction); ok {
+		if back := neighbour.roads(!closed)[opposite]; back != nil && back.To == city {
+			neighbour.setRoad(opposite, back, closed)
 		}
-		city.West = westCity
-		westCity.East = city
-		m.Cities[west] = westCity
-	}
-	if south != "" {
-		southCity := m.Cities[south]
-		if southCity == nil {
-			southCity = &City{Name: south, East: nil, North: city, West: nil, South: nil, Aliens: make(map[string]bool)}
+	}
+}
+
+func (m *worldMapImpl) DestroyRoad(from string, direction string) error {
+	city := m.Cities[from]
+	if city == nil || city.Roads[direction] == nil {
+		return fmt.Errorf("no road from %s in %s direction", from, direction)
+	}
+	neighbour := city.Roads[direction].To
+	delete(city.Roads, direction)
+	if opposite, ok := m.topology.Opposite(direction); ok {
+		if back := neighbour.Roads[opposite]; back != nil && back.To == city {
+			delete(neighbour.Roads, opposite)
 		}
-		city.South = southCity
-		southCity.North = city
-		m.Cities[south] = southCity
 	}
+	return nil
+}
+
+func (m *worldMapImpl) SetMetadata(city string, key string, value string) error {
+	if m.Cities[city] == nil {
+		return fmt.Errorf("trying to set %s of non-existing city %s", key, city)
+	}
+	m.Cities[city].Metadata[key] = value
+	return nil
+}
 
-	m.Cities[name] = city
+func (m *worldMapImpl) getOrCreateCity(name string) *City {
+	city := m.Cities[name]
+	if city == nil {
+		city = &City{Name: name, Roads: make(map[string]*Road), Closed: make(map[string]*Road), Portals: make(map[string]*Portal), Aliens: make(map[string]bool), Metadata: make(map[string]string), Topology: m.topology}
+		m.Cities[name] = city
+	}
+	return city
 }
 
 func (m *worldMapImpl) AddAlien(alien *Alien) error {
@@ -162,46 +416,168 @@ func (m *worldMapImpl) AddAlien(alien *Alien) error {
 	return nil
 }
 
-func (m *worldMapImpl) MoveAlien(alien *Alien, rng *rand.Rand) {
+func (m *worldMapImpl) RemoveAlien(name string) {
+	alien := m.Aliens[name]
+	if alien == nil {
+		return
+	}
+	if city := m.Cities[alien.City]; city != nil {
+		delete(city.Aliens, name)
+	}
+	delete(m.Aliens, name)
+}
+
+func (m *worldMapImpl) MoveAlien(alien *Alien, rng *rand.Rand) bool {
+	if alien.Transit != nil {
+		m.moveInTransit(alien)
+		return false
+	}
 	city := m.Cities[alien.City]
+	if m.teleport(alien, city, rng) {
+		return true
+	}
 	directions := city.GetDirections()
 	if len(directions) > 0 {
-		direction := directions[rng.Intn(len(directions))]
-		newCity, err := city.GetNeighbour(direction)
-		if err == nil {
-			alien.City = newCity
-			delete(city.Aliens, alien.Name)
-			m.Cities[alien.City].Aliens[alien.Name] = true
-		} else {
+		if err := m.MoveAlienAlong(alien, pickDirection(city, directions, rng)); err != nil {
 			log.Println(err)
 		}
 	}
+	return false
 }
 
-func (m *worldMapImpl) DestroyCity(cityToDestroy string) {
-	city := m.Cities[cityToDestroy]
-	if len(city.Aliens) > 1 {
-		if city.East != nil {
-			city.East.West = nil
+func (m *worldMapImpl) MoveAlienAlong(alien *Alien, direction string) error {
+	if alien.Transit != nil {
+		m.moveInTransit(alien)
+		return nil
+	}
+	city := m.Cities[alien.City]
+	if city == nil {
+		return fmt.Errorf("alien %s is in non-existing city %s", alien.Name, alien.City)
+	}
+	newCity, err := city.GetNeighbour(direction)
+	if err != nil {
+		return err
+	}
+	delete(city.Aliens, alien.Name)
+	if length := city.Roads[direction].Length; length > 1 {
+		alien.City = ""
+		alien.Transit = &Transit{From: city.Name, Direction: direction, To: newCity, Length: length, Remaining: length - 1}
+		return nil
+	}
+	alien.City = newCity
+	m.Cities[alien.City].Aliens[alien.Name] = true
+	return nil
+}
+
+// teleport tries portals of the city one by one and moves the alien through the first
+// portal it traverses. It returns false if the alien hasn't used any portal.
+func (m *worldMapImpl) teleport(alien *Alien, city *City, rng *rand.Rand) bool {
+	for _, name := range city.PortalNames() {
+		portal := city.Portals[name]
+		if rng.Float64() >= portal.Chance {
+			continue
 		}
-		if city.North != nil {
-			city.North.South = nil
+		delete(city.Aliens, alien.Name)
+		alien.City = portal.To.Name
+		portal.To.Aliens[alien.Name] = true
+		log.Printf("Alien %s has teleported from %s to %s through portal %s", alien.Name, city.Name, alien.City, name)
+		return true
+	}
+	return false
+}
+
+// moveInTransit moves the alien one step further along the road. If the destination
+// has been destroyed meanwhile, the alien turns back. If both ends of the road
+// are destroyed, the alien remains stranded on the road.
+func (m *worldMapImpl) moveInTransit(alien *Alien) {
+	transit := alien.Transit
+	if m.Cities[transit.To] == nil {
+		if m.Cities[transit.From] == nil {
+			return
+		}
+		opposite, _ := m.topology.Opposite(transit.Direction)
+		transit.From, transit.To, transit.Direction = transit.To, transit.From, opposite
+		transit.Remaining = transit.Length - transit.Remaining
+	}
+	transit.Remaining--
+	if transit.Remaining == 0 {
+		alien.Transit = nil
+		alien.City = transit.To
+		m.Cities[alien.City].Aliens[alien.Name] = true
+	}
+}
+
+func (m *worldMapImpl) DestroyCity(cityToDestroy string) []string {
+	city := m.Cities[cityToDestroy]
+	// the city may have already collapsed during the same fight check
+	if city != nil && len(city.Aliens) > 1 {
+		aliens := m.RuinCity(cityToDestroy)
+		log.Printf("%s has been destroyed by aliens %s", cityToDestroy, strings.Join(aliens, " "))
+		return aliens
+	}
+	return nil
+}
+
+func (m *worldMapImpl) RuinCity(name string) []string {
+	city := m.Cities[name]
+	if city == nil {
+		return nil
+	}
+	m.bury(city)
+	// one-way roads may lead into the city from anywhere so check all the cities
+	for _, other := range m.Cities {
+		for direction, road := range other.Roads {
+			if road.To == city {
+				delete(other.Roads, direction)
+			}
 		}
-		if city.West != nil {
-			city.West.East = nil
+		for direction, road := range other.Closed {
+			if road.To == city {
+				delete(other.Closed, direction)
+			}
 		}
-		if city.South != nil {
-			city.South.North = nil
+		for name, portal := range other.Portals {
+			if portal.To == city {
+				delete(other.Portals, name)
+			}
 		}
-		delete(m.Cities, city.Name)
-		for alien := range city.Aliens {
-			delete(m.Aliens, alien)
+	}
+	delete(m.Cities, city.Name)
+	aliens := make([]string, 0, len(city.Aliens))
+	for alien := range city.Aliens {
+		delete(m.Aliens, alien)
+		aliens = append(aliens, alien)
+	}
+	sort.Strings(aliens)
+	city.Aliens = nil
+	return aliens
+}
+
+// TrappedAliens returns sorted names of aliens staying in cities without open roads and portals.
+func TrappedAliens(worldMap WorldMap) []string {
+	trapped := make([]string, 0)
+	for name, alien := range worldMap.GetAliens() {
+		if city := worldMap.GetCities()[alien.City]; city != nil && len(city.GetDirections()) == 0 && len(city.Portals) == 0 {
+			trapped = append(trapped, name)
 		}
-		aliens := ""
-		for alien := range city.Aliens {
-			aliens += alien + " "
+	}
+	sort.Strings(trapped)
+	return trapped
+}
+
+// pickDirection chooses one of directions randomly with probability
+// proportional to the road weight.
+func pickDirection(city *City, directions []string, rng *rand.Rand) string {
+	total := 0.0
+	for _, direction := range directions {
+		total += city.Roads[direction].Weight
+	}
+	choice := rng.Float64() * total
+	for _, direction := range directions {
+		choice -= city.Roads[direction].Weight
+		if choice < 0 {
+			return direction
 		}
-		log.Printf("%s has been destroyed by aliens %s", cityToDestroy, aliens)
-		city.Aliens = nil
 	}
+	return directions[len(directions)-1]
 }
@@ -19,17 +19,17 @@ func TestInitWorldMap(t *testing.T) {
 func TestSingleCityMap(t *testing.T) {
 	wm := InitWorldMap()
 	name := "Heidelberg"
-	wm.AddCity(name, "", "", "", "")
+	assert.NilError(t, wm.AddCity(name, map[string]string{}))
 	// only 1 city and no aliens so far
 	assert.Assert(t, len(wm.GetCities()) == 1)
 	assert.Assert(t, len(wm.GetAliens()) == 0)
 	// still the same city without neighbours
 	city := wm.GetCities()[name]
 	assert.Assert(t, city.Name == name)
-	assert.Assert(t, city.East == nil)
-	assert.Assert(t, city.North == nil)
-	assert.Assert(t, city.West == nil)
-	assert.Assert(t, city.South == nil)
+	assert.Assert(t, city.Roads["east"] == nil)
+	assert.Assert(t, city.Roads["north"] == nil)
+	assert.Assert(t, city.Roads["west"] == nil)
+	assert.Assert(t, city.Roads["south"] == nil)
 }
 
 func TestMultipleCitiesMap(t *testing.T) {
@@ -37,71 +37,71 @@ func TestMultipleCitiesMap(t *testing.T) {
 	wm := createSimpleMap()
 	// now let's check that all the cities are saved according to the scheme above
 	// Berlin
-	assert.Assert(t, wm.GetCities()[cities[4]].East == nil)
-	assert.Assert(t, wm.GetCities()[cities[4]].North == nil)
-	assert.Assert(t, wm.GetCities()[cities[4]].West.Name == cities[1])
-	assert.Assert(t, wm.GetCities()[cities[4]].South == nil)
+	assert.Assert(t, wm.GetCities()[cities[4]].Roads["east"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[4]].Roads["north"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[4]].Roads["west"].To.Name == cities[1])
+	assert.Assert(t, wm.GetCities()[cities[4]].Roads["south"] == nil)
 
 	// Cologne
-	assert.Assert(t, wm.GetCities()[cities[1]].East.Name == cities[4])
-	assert.Assert(t, wm.GetCities()[cities[1]].North == nil)
-	assert.Assert(t, wm.GetCities()[cities[1]].West == nil)
-	assert.Assert(t, wm.GetCities()[cities[1]].South.Name == cities[2])
+	assert.Assert(t, wm.GetCities()[cities[1]].Roads["east"].To.Name == cities[4])
+	assert.Assert(t, wm.GetCities()[cities[1]].Roads["north"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[1]].Roads["west"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[1]].Roads["south"].To.Name == cities[2])
 
 	// Frankfurt
-	assert.Assert(t, wm.GetCities()[cities[2]].East.Name == cities[6])
-	assert.Assert(t, wm.GetCities()[cities[2]].North.Name == cities[1])
-	assert.Assert(t, wm.GetCities()[cities[2]].West.Name == cities[5])
-	assert.Assert(t, wm.GetCities()[cities[2]].South.Name == cities[0])
+	assert.Assert(t, wm.GetCities()[cities[2]].Roads["east"].To.Name == cities[6])
+	assert.Assert(t, wm.GetCities()[cities[2]].Roads["north"].To.Name == cities[1])
+	assert.Assert(t, wm.GetCities()[cities[2]].Roads["west"].To.Name == cities[5])
+	assert.Assert(t, wm.GetCities()[cities[2]].Roads["south"].To.Name == cities[0])
 
 	// Strasbourg
-	assert.Assert(t, wm.GetCities()[cities[5]].East.Name == cities[2])
-	assert.Assert(t, wm.GetCities()[cities[5]].North == nil)
-	assert.Assert(t, wm.GetCities()[cities[5]].West == nil)
-	assert.Assert(t, wm.GetCities()[cities[5]].South == nil)
+	assert.Assert(t, wm.GetCities()[cities[5]].Roads["east"].To.Name == cities[2])
+	assert.Assert(t, wm.GetCities()[cities[5]].Roads["north"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[5]].Roads["west"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[5]].Roads["south"] == nil)
 
 	// Nuremberg
-	assert.Assert(t, wm.GetCities()[cities[6]].East == nil)
-	assert.Assert(t, wm.GetCities()[cities[6]].North == nil)
-	assert.Assert(t, wm.GetCities()[cities[6]].West.Name == cities[2])
-	assert.Assert(t, wm.GetCities()[cities[6]].South.Name == cities[3])
+	assert.Assert(t, wm.GetCities()[cities[6]].Roads["east"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[6]].Roads["north"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[6]].Roads["west"].To.Name == cities[2])
+	assert.Assert(t, wm.GetCities()[cities[6]].Roads["south"].To.Name == cities[3])
 
 	// Heidelberg
-	assert.Assert(t, wm.GetCities()[cities[0]].East.Name == cities[3])
-	assert.Assert(t, wm.GetCities()[cities[0]].North.Name == cities[2])
-	assert.Assert(t, wm.GetCities()[cities[0]].West == nil)
-	assert.Assert(t, wm.GetCities()[cities[0]].South == nil)
+	assert.Assert(t, wm.GetCities()[cities[0]].Roads["east"].To.Name == cities[3])
+	assert.Assert(t, wm.GetCities()[cities[0]].Roads["north"].To.Name == cities[2])
+	assert.Assert(t, wm.GetCities()[cities[0]].Roads["west"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[0]].Roads["south"] == nil)
 
 	// Munich
-	assert.Assert(t, wm.GetCities()[cities[3]].East == nil)
-	assert.Assert(t, wm.GetCities()[cities[3]].North.Name == cities[6])
-	assert.Assert(t, wm.GetCities()[cities[3]].West.Name == cities[0])
-	assert.Assert(t, wm.GetCities()[cities[3]].South == nil)
+	assert.Assert(t, wm.GetCities()[cities[3]].Roads["east"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[3]].Roads["north"].To.Name == cities[6])
+	assert.Assert(t, wm.GetCities()[cities[3]].Roads["west"].To.Name == cities[0])
+	assert.Assert(t, wm.GetCities()[cities[3]].Roads["south"] == nil)
 
 	// Regensburg
-	assert.Assert(t, wm.GetCities()[cities[7]].East == nil)
-	assert.Assert(t, wm.GetCities()[cities[7]].North.Name == cities[8])
-	assert.Assert(t, wm.GetCities()[cities[7]].West == nil)
-	assert.Assert(t, wm.GetCities()[cities[7]].South == nil)
+	assert.Assert(t, wm.GetCities()[cities[7]].Roads["east"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[7]].Roads["north"].To.Name == cities[8])
+	assert.Assert(t, wm.GetCities()[cities[7]].Roads["west"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[7]].Roads["south"] == nil)
 
 	// Leipzig
-	assert.Assert(t, wm.GetCities()[cities[8]].East == nil)
-	assert.Assert(t, wm.GetCities()[cities[8]].North == nil)
-	assert.Assert(t, wm.GetCities()[cities[8]].West == nil)
-	assert.Assert(t, wm.GetCities()[cities[8]].South.Name == cities[7])
+	assert.Assert(t, wm.GetCities()[cities[8]].Roads["east"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[8]].Roads["north"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[8]].Roads["west"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[8]].Roads["south"].To.Name == cities[7])
 }
 
 func TestAddCityAssymetric(t *testing.T) {
 	wm := InitWorldMap()
-	wm.AddCity("Frankfurt", "", "", "", "Heidelberg")
-	wm.AddCity("Heidelberg", "", "", "", "")
-	assert.Assert(t, wm.GetCities()["Frankfurt"].South.Name == "Heidelberg")
-	assert.Assert(t, wm.GetCities()["Heidelberg"].North.Name == "Frankfurt")
+	wm.AddCity("Frankfurt", map[string]string{"south": "Heidelberg"})
+	wm.AddCity("Heidelberg", map[string]string{})
+	assert.Assert(t, wm.GetCities()["Frankfurt"].Roads["south"].To.Name == "Heidelberg")
+	assert.Assert(t, wm.GetCities()["Heidelberg"].Roads["north"].To.Name == "Frankfurt")
 }
 
 func TestAddAlien(t *testing.T) {
 	wm := InitWorldMap()
-	wm.AddCity("Zurich", "", "Frankfurt", "", "Milan")
+	wm.AddCity("Zurich", map[string]string{"north": "Frankfurt", "south": "Milan"})
 	assert.Assert(t, wm.AddAlien(&Alien{Name: "The Evil", City: "Zurich"}) == nil)
 	// Alien should be added as expected
 	assert.Assert(t, wm.GetAliens()["The Evil"].City == "Zurich")
@@ -117,14 +117,14 @@ func TestMoveAlien(t *testing.T) {
 	firstCity := "Prague"
 	secondCity := "Amsterdam"
 	wm := InitWorldMap()
-	wm.AddCity(firstCity, "", "", "", "")
+	wm.AddCity(firstCity, map[string]string{})
 	alien := &Alien{Name: "Lazy cat", City: firstCity}
 	wm.AddAlien(alien)
 	// Trying to move alien but there are no cities to move to
 	wm.MoveAlien(alien, rng)
 	assert.Assert(t, alien.City == firstCity)
 	// Now add another city connected with Prague
-	wm.AddCity(secondCity, firstCity, "", "", "")
+	wm.AddCity(secondCity, map[string]string{"east": firstCity})
 	// Now alien should be in Amsterdam
 	wm.MoveAlien(alien, rng)
 }
@@ -141,16 +141,16 @@ func TestDestroyCity(t *testing.T) {
 	assert.Assert(t, wm.GetAliens()[alien2.Name] == nil)
 	assert.Assert(t, wm.GetCities()[alien1.Name] == nil)
 	// Frankfurt connections are also destroyed now
-	assert.Assert(t, wm.GetCities()[cities[6]].West == nil)
-	assert.Assert(t, wm.GetCities()[cities[1]].South == nil)
-	assert.Assert(t, wm.GetCities()[cities[5]].East == nil)
-	assert.Assert(t, wm.GetCities()[cities[0]].North == nil)
+	assert.Assert(t, wm.GetCities()[cities[6]].Roads["west"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[1]].Roads["south"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[5]].Roads["east"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[0]].Roads["north"] == nil)
 }
 
 func TestGetDirections(t *testing.T) {
 	// it's not necessary to create a WorldMap instance here but it's easier to test this way
 	wm := InitWorldMap()
-	wm.AddCity("Hannover", "Berlin", "Hamburg", "Cologne", "Mainz")
+	wm.AddCity("Hannover", map[string]string{"east": "Berlin", "north": "Hamburg", "west": "Cologne", "south": "Mainz"})
 	hannoverDirections := wm.GetCities()["Hannover"].GetDirections()
 	assert.Assert(t, len(hannoverDirections) == 4)
 	assert.Assert(t, hannoverDirections[0] == "east")
@@ -163,33 +163,33 @@ func TestGetDirections(t *testing.T) {
 }
 
 func TestGetNeighbour(t *testing.T) {
-	city := City{Name: "Geneva", Aliens: make(map[string]bool)}
+	city := City{Name: "Geneva", Roads: make(map[string]*Road), Aliens: make(map[string]bool), Topology: CompassTopology}
 	// east direction
 	_, err := city.GetNeighbour("east")
 	assert.Error(t, err, "no cities in east direction")
-	eastCity := &City{Name: "Milan", East: nil, North: nil, West: &city, South: nil, Aliens: make(map[string]bool)}
-	city.East = eastCity
+	eastCity := &City{Name: "Milan", Roads: map[string]*Road{"west": {To: &city}}, Aliens: make(map[string]bool)}
+	city.Roads["east"] = &Road{To: eastCity}
 	milan, _ := city.GetNeighbour("east")
 	assert.Assert(t, milan == eastCity.Name)
 	// north direction
 	_, err = city.GetNeighbour("north")
 	assert.Error(t, err, "no cities in north direction")
-	northCity := &City{Name: "Bern", East: nil, North: nil, West: &city, South: nil, Aliens: make(map[string]bool)}
-	city.North = northCity
+	northCity := &City{Name: "Bern", Roads: map[string]*Road{"west": {To: &city}}, Aliens: make(map[string]bool)}
+	city.Roads["north"] = &Road{To: northCity}
 	bern, _ := city.GetNeighbour("north")
 	assert.Assert(t, bern == northCity.Name)
 	// west direction
 	_, err = city.GetNeighbour("west")
 	assert.Error(t, err, "no cities in west direction")
-	westCity := &City{Name: "Lyon", East: nil, North: nil, West: &city, South: nil, Aliens: make(map[string]bool)}
-	city.West = westCity
+	westCity := &City{Name: "Lyon", Roads: map[string]*Road{"west": {To: &city}}, Aliens: make(map[string]bool)}
+	city.Roads["west"] = &Road{To: westCity}
 	lyon, _ := city.GetNeighbour("west")
 	assert.Assert(t, lyon == westCity.Name)
 	// south direction
 	_, err = city.GetNeighbour("south")
 	assert.Error(t, err, "no cities in south direction")
-	southCity := &City{Name: "Marseille", East: nil, North: nil, West: &city, South: nil, Aliens: make(map[string]bool)}
-	city.South = southCity
+	southCity := &City{Name: "Marseille", Roads: map[string]*Road{"west": {To: &city}}, Aliens: make(map[string]bool)}
+	city.Roads["south"] = &Road{To: southCity}
 	marseille, _ := city.GetNeighbour("south")
 	assert.Assert(t, marseille == southCity.Name)
 
@@ -197,24 +197,269 @@ func TestGetNeighbour(t *testing.T) {
 	assert.Error(t, err, "wrong direction wrong")
 }
 
-func createSimpleMap() WorldMap {
-	// the map how it's supposed to look like (check first letters; *slightly* different to the real life)
-	/*
-			C - B   L
-			|		|
-		S - F - N - R
-			|	|
-			H - M
-	*/
-	wm := InitWorldMap()
-	wm.AddCity(cities[4], "", "", cities[1], "")
-	wm.AddCity(cities[1], cities[4], "", "", cities[2])
-	wm.AddCity(cities[2], cities[6], cities[1], cities[5], cities[0])
-	wm.AddCity(cities[5], cities[2], "", "", "")
-	wm.AddCity(cities[6], "", "", cities[2], cities[3])
-	wm.AddCity(cities[0], cities[3], cities[2], "", "")
-	wm.AddCity(cities[3], "", cities[6], cities[0], "")
-	wm.AddCity(cities[7], "", cities[8], "", "")
+func TestAddCityWrongDirection(t *testing.T) {
+	wm := InitWorldMap()
+	assert.Error(t, wm.AddCity("Hannover", map[string]string{"east": "Berlin", "up": "Moon"}), "wrong direction up")
+	// nothing should be added if any of directions is wrong
+	assert.Assert(t, len(wm.GetCities()) == 0)
+}
+
+func TestHexTopologyMap(t *testing.T) {
+	wm := InitWorldMapWithTopology(HexTopology)
+	assert.NilError(t, wm.AddCity("Hive", map[string]string{"northeast": "Nest", "southwest": "Burrow", "east": "Lair"}))
+	assert.Assert(t, wm.GetCities()["Nest"].Roads["southwest"].To.Name == "Hive")
+	assert.Assert(t, wm.GetCities()["Burrow"].Roads["northeast"].To.Name == "Hive")
+	assert.Assert(t, wm.GetCities()["Lair"].Roads["west"].To.Name == "Hive")
+	// directions are reported in topology order
+	assert.DeepEqual(t, wm.GetCities()["Hive"].GetDirections(), []string{"east", "northeast", "southwest"})
+	_, err := wm.GetCities()["Hive"].GetNeighbour("north")
+	assert.Error(t, err, "wrong direction north")
+	assert.Error(t, wm.AddCity("Hive", map[string]string{"north": "Nest"}), "wrong direction north")
+}
+
+func TestDestroyCityCubeTopology(t *testing.T) {
+	wm := InitWorldMapWithTopology(CubeTopology)
+	wm.AddCity("Core", map[string]string{"up": "Surface", "down": "Mantle", "east": "Tunnel"})
+	wm.AddAlien(&Alien{Name: "Digger", City: "Core"})
+	wm.AddAlien(&Alien{Name: "Driller", City: "Core"})
+	wm.DestroyCity("Core")
+	assert.Assert(t, wm.GetCities()["Core"] == nil)
+	assert.Assert(t, len(wm.GetCities()["Surface"].GetDirections()) == 0)
+	assert.Assert(t, len(wm.GetCities()["Mantle"].GetDirections()) == 0)
+	assert.Assert(t, len(wm.GetCities()["Tunnel"].GetDirections()) == 0)
+}
+
+func TestAddRoadOneWay(t *testing.T) {
+	wm := InitWorldMap()
+	assert.NilError(t, wm.AddRoad("Ferry", "east", "Island", RoadOptions{OneWay: true}))
+	assert.Assert(t, wm.GetCities()["Ferry"].Roads["east"].To.Name == "Island")
+	assert.Assert(t, wm.GetCities()["Ferry"].Roads["east"].Weight == 1)
+	assert.Assert(t, len(wm.GetCities()["Island"].GetDirections()) == 0)
+	assert.Assert(t, !wm.GetCities()["Ferry"].IsTwoWay("east"))
+	// the way back with a different weight still doesn't make the road two-way
+	assert.NilError(t, wm.AddRoad("Island", "west", "Ferry", RoadOptions{OneWay: true, Weight: 3}))
+	assert.Assert(t, !wm.GetCities()["Ferry"].IsTwoWay("east"))
+	assert.NilError(t, wm.AddRoad("Island", "west", "Ferry", RoadOptions{OneWay: true}))
+	assert.Assert(t, wm.GetCities()["Ferry"].IsTwoWay("east"))
+
+	assert.Error(t, wm.AddRoad("Ferry", "up", "Sky", RoadOptions{}), "wrong direction up")
+	assert.Error(t, wm.AddRoad("Ferry", "north", "Sky", RoadOptions{Weight: -1}), "road weight should be positive but got -1")
+}
+
+func TestMoveAlienWeighted(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	wm := InitWorldMap()
+	wm.AddRoad("Pass", "north", "Peak", RoadOptions{Weight: 99, OneWay: true})
+	wm.AddRoad("Pass", "south", "Valley", RoadOptions{Weight: 1, OneWay: true})
+	alien := &Alien{Name: "Climber", City: "Pass"}
+	wm.AddAlien(alien)
+	toPeak := 0
+	for i := 0; i < 1000; i++ {
+		alien.City = "Pass"
+		wm.MoveAlien(alien, rng)
+		if alien.City == "Peak" {
+			toPeak++
+		}
+	}
+	assert.Assert(t, toPeak > 950)
+	// one-way roads don't allow to come back
+	wm.MoveAlien(alien, rng)
+	assert.Assert(t, alien.City == "Peak" || alien.City == "Valley")
+}
+
+func TestDestroyCityOneWay(t *testing.T) {
+	wm := InitWorldMap()
+	wm.AddRoad("Upstream", "south", "Downstream", RoadOptions{OneWay: true})
+	wm.AddAlien(&Alien{Name: "Rafter", City: "Downstream"})
+	wm.AddAlien(&Alien{Name: "Swimmer", City: "Downstream"})
+	wm.DestroyCity("Downstream")
+	assert.Assert(t, wm.GetCities()["Upstream"].Roads["south"] == nil)
+}
+
+func TestMoveAlienLongRoad(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	wm := InitWorldMap()
+	wm.AddRoad("Base", "north", "Summit", RoadOptions{Length: 3})
+	alien := &Alien{Name: "Hiker", City: "Base"}
+	wm.AddAlien(alien)
+	wm.MoveAlien(alien, rng)
+	// the alien left the city but hasn't arrived yet
+	assert.Assert(t, alien.City == "")
+	assert.Assert(t, len(wm.GetCities()["Base"].Aliens) == 0)
+	assert.DeepEqual(t, *alien.Transit, Transit{From: "Base", Direction: "north", To: "Summit", Length: 3, Remaining: 2})
+	assert.Assert(t, alien.Transit.Progress() == 1)
+	wm.MoveAlien(alien, rng)
+	assert.Assert(t, alien.Transit.Remaining == 1)
+	wm.MoveAlien(alien, rng)
+	assert.Assert(t, alien.Transit == nil)
+	assert.Assert(t, alien.City == "Summit")
+	assert.Assert(t, wm.GetCities()["Summit"].Aliens["Hiker"])
+}
+
+func TestMoveAlienTurnsBack(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	wm := InitWorldMap()
+	wm.AddRoad("Base", "north", "Summit", RoadOptions{Length: 5})
+	alien := &Alien{Name: "Hiker", City: "Base"}
+	wm.AddAlien(alien)
+	wm.MoveAlien(alien, rng)
+	wm.MoveAlien(alien, rng)
+	// destination is destroyed while the alien is on the road
+	wm.AddAlien(&Alien{Name: "Yeti", City: "Summit"})
+	wm.AddAlien(&Alien{Name: "Bigfoot", City: "Summit"})
+	wm.DestroyCity("Summit")
+	wm.MoveAlien(alien, rng)
+	assert.DeepEqual(t, *alien.Transit, Transit{From: "Summit", Direction: "south", To: "Base", Length: 5, Remaining: 1})
+	wm.MoveAlien(alien, rng)
+	assert.Assert(t, alien.City == "Base")
+}
 
+func TestRemoveAlien(t *testing.T) {
+	wm := InitWorldMap()
+	wm.AddCity("Roswell", map[string]string{})
+	wm.AddAlien(&Alien{Name: "Grey", City: "Roswell"})
+	wm.RemoveAlien("Grey")
+	assert.Assert(t, len(wm.GetAliens()) == 0)
+	assert.Assert(t, len(wm.GetCities()["Roswell"].Aliens) == 0)
+	// removing non-existing alien does nothing
+	wm.RemoveAlien("Grey")
+}
+
+func TestDestroyRoad(t *testing.T) {
+	wm := createSimpleMap()
+	assert.NilError(t, wm.DestroyRoad(cities[2], "east"))
+	assert.Assert(t, wm.GetCities()[cities[2]].Roads["east"] == nil)
+	assert.Assert(t, wm.GetCities()[cities[6]].Roads["west"] == nil)
+	// other roads remain untouched
+	assert.Assert(t, wm.GetCities()[cities[2]].Roads["north"].To.Name == cities[1])
+	assert.Error(t, wm.DestroyRoad(cities[2], "east"), "no road from Frankfurt in east direction")
+	// only the given direction of a one-way road is destroyed
+	wm.AddRoad("Here", "east", "There", RoadOptions{OneWay: true})
+	wm.AddRoad("There", "west", "Here", RoadOptions{OneWay: true, Weight: 2})
+	assert.NilError(t, wm.DestroyRoad("Here", "east"))
+	assert.Assert(t, wm.GetCities()["There"].Roads["west"] == nil)
+}
+
+func TestSetMetadata(t *testing.T) {
+	wm := InitWorldMap()
+	wm.AddCity("Vienna", map[string]string{})
+	assert.NilError(t, wm.SetMetadata("Vienna", "population", "1900000"))
+	population, err := wm.GetCities()["Vienna"].GetMetadataFloat("population", 0)
+	assert.NilError(t, err)
+	assert.Assert(t, population == 1900000)
+	height, err := wm.GetCities()["Vienna"].GetMetadataFloat("height", 42)
+	assert.NilError(t, err)
+	assert.Assert(t, height == 42)
+	wm.SetMetadata("Vienna", "height", "high")
+	_, err = wm.GetCities()["Vienna"].GetMetadataFloat("height", 42)
+	assert.Error(t, err, "city Vienna has non-numeric height: high")
+	assert.Error(t, wm.SetMetadata("Linz", "population", "1"), "trying to set population of non-existing city Linz")
+}
+
+func createSimpleMap() WorldMap {
+	// check first letters; *slightly* different to the real life
+	wm, err := ParseASCIIMap([]string{
+		"    C - B   L",
+		"    |       |",
+		"S - F - N   R",
+		"    |   |",
+		"    H - M",
+		"",
+		"H=Heidelberg",
+		"C=Cologne",
+		"F=Frankfurt",
+		"M=Munich",
+		"B=Berlin",
+		"S=Strasbourg",
+		"N=Nuremberg",
+		"R=Regensburg",
+		"L=Leipzig",
+	}, CompassTopology)
+	if err != nil {
+		panic(err)
+	}
 	return wm
 }
+
+func TestCloseAndReopenRoad(t *testing.T) {
+	wm := InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddRoad("A", "north", "C", RoadOptions{OneWay: true})
+	a, b := wm.GetCities()["A"], wm.GetCities()["B"]
+	assert.NilError(t, wm.CloseRoad("B", "west"))
+	assert.Assert(t, a.Roads["east"] == nil && b.Roads["west"] == nil)
+	assert.Assert(t, a.Closed["east"].To == b && b.Closed["west"].To == a)
+	assert.DeepEqual(t, a.GetDirections(), []string{"north"})
+	assert.Assert(t, a.IsTwoWay("east"))
+	// aliens can't leave B while its only road is closed
+	alien := &Alien{Name: "X", City: "B"}
+	wm.AddAlien(alien)
+	wm.MoveAlien(alien, rand.New(rand.NewSource(0)))
+	assert.Assert(t, alien.City == "B")
+	assert.Error(t, wm.CloseRoad("A", "east"), "no open road from A in east direction")
+	assert.Error(t, wm.ReopenRoad("A", "north"), "no closed road from A in north direction")
+
+	assert.DeepEqual(t, TrappedAliens(wm), []string{"X"})
+
+	assert.NilError(t, wm.ReopenRoad("A", "east"))
+	assert.DeepEqual(t, TrappedAliens(wm), []string{})
+	assert.Assert(t, a.Roads["east"].To == b && b.Roads["west"].To == a)
+	assert.Assert(t, len(a.Closed) == 0 && len(b.Closed) == 0)
+
+	// closing a one-way road doesn't touch anything else
+	assert.NilError(t, wm.CloseRoad("A", "north"))
+	assert.Assert(t, a.Closed["north"] != nil)
+	assert.Assert(t, len(wm.GetCities()["C"].Closed) == 0)
+}
+
+func TestDestroyCityWithClosedRoads(t *testing.T) {
+	wm := InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.CloseRoad("A", "east")
+	wm.AddAlien(&Alien{Name: "X", City: "B"})
+	wm.AddAlien(&Alien{Name: "Y", City: "B"})
+	wm.DestroyCity("B")
+	assert.Assert(t, len(wm.GetCities()["A"].Closed) == 0)
+	// closed roads are restored closed when the city is rebuilt
+	assert.NilError(t, wm.RebuildCity("B"))
+	assert.Assert(t, wm.GetCities()["A"].Closed["east"] != nil)
+	assert.Assert(t, wm.GetCities()["B"].Closed["west"] != nil)
+}
+
+func TestRuinCity(t *testing.T) {
+	wm := InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddAlien(&Alien{Name: "X", City: "B"})
+	// a single alien doesn't destroy the city but the city can be ruined anyway
+	assert.Assert(t, wm.DestroyCity("B") == nil)
+	assert.DeepEqual(t, wm.RuinCity("B"), []string{"X"})
+	assert.Assert(t, wm.GetCities()["B"] == nil)
+	assert.Assert(t, wm.GetAliens()["X"] == nil)
+	assert.Assert(t, len(wm.GetCities()["A"].Roads) == 0)
+	assert.Assert(t, wm.GetRuins()["B"] != nil)
+	assert.Assert(t, wm.RuinCity("B") == nil)
+}
+
+func TestPortals(t *testing.T) {
+	wm := InitWorldMap()
+	assert.NilError(t, wm.AddRoad("A", "east", "B", RoadOptions{}))
+	assert.NilError(t, wm.AddPortal("A", "Gate", "C", PortalOptions{Chance: 1}))
+	assert.NilError(t, wm.AddPortal("B", "Wormhole", "C", PortalOptions{OneWay: true}))
+	assert.Error(t, wm.AddPortal("A", "Gate", "B", PortalOptions{Chance: 2}), "portal chance should be between 0 and 1 but got 2")
+	built := wm.GetCities()
+	assert.Assert(t, built["A"].IsTwoWayPortal("Gate"))
+	assert.Assert(t, !built["B"].IsTwoWayPortal("Wormhole"))
+	assert.Assert(t, built["B"].Portals["Wormhole"].Chance == 0.5)
+
+	alien := &Alien{Name: "X", City: "A"}
+	wm.AddAlien(alien)
+	assert.Assert(t, wm.MoveAlien(alien, rand.New(rand.NewSource(0))))
+	assert.Assert(t, alien.City == "C" && built["C"].Aliens["X"] && !built["A"].Aliens["X"])
+
+	// portals leading into a destroyed city disappear, the ruin restores them
+	wm.RuinCity("C")
+	assert.Assert(t, len(built["A"].Portals) == 0 && len(built["B"].Portals) == 0)
+	assert.NilError(t, wm.RebuildCity("C"))
+	assert.Assert(t, built["A"].IsTwoWayPortal("Gate"))
+	assert.Assert(t, built["B"].Portals["Wormhole"].To == built["C"])
+}
@@ -0,0 +1,113 @@
+package world
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Grid describes a rectangular world of Width x Height cities connected with compass roads.
+// Cities are named r<row>c<column> starting from r0c0 in the north-west corner.
+type Grid struct {
+	Width  uint32
+	Height uint32
+	// WrapX connects the east edge with the west edge, together with WrapY it makes a torus.
+	WrapX bool
+	// WrapY connects the south edge with the north edge.
+	WrapY bool
+	// Holes is probability of every city to be missing from the grid.
+	Holes float64
+}
+
+// gridShapes keeps wrapping of every named grid shape.
+var gridShapes = map[string][2]bool{
+	"grid":     {false, false},
+	"cylinder": {true, false},
+	"torus":    {true, true},
+}
+
+// ParseGrid creates a grid from its description: <shape>:<width>x<height>[,holes:<p>]
+// where shape is grid, cylinder (wrapping east and west edges) or torus, e.g. "torus:20x10,holes:0.1".
+func ParseGrid(spec string) (Grid, error) {
+	grid := Grid{}
+	options := strings.Split(spec, ",")
+	shape := strings.Split(options[0], ":")
+	wrap, ok := gridShapes[shape[0]]
+	if !ok || len(shape) != 2 {
+		return grid, fmt.Errorf("expected grid, cylinder or torus:<width>x<height> but got %s", options[0])
+	}
+	grid.WrapX, grid.WrapY = wrap[0], wrap[1]
+	size := strings.Split(shape[1], "x")
+	if len(size) != 2 {
+		return grid, fmt.Errorf("expected <width>x<height> but got %s", shape[1])
+	}
+	for i, value := range []*uint32{&grid.Width, &grid.Height} {
+		number, err := strconv.ParseUint(size[i], 10, 32)
+		if err != nil || number == 0 {
+			return grid, fmt.Errorf("grid size should be a positive integer but got %s", size[i])
+		}
+		*value = uint32(number)
+	}
+	for _, option := range options[1:] {
+		keyValue := strings.Split(option, ":")
+		if len(keyValue) != 2 || keyValue[0] != "holes" {
+			return grid, fmt.Errorf("unknown grid option %s", option)
+		}
+		holes, err := strconv.ParseFloat(keyValue[1], 64)
+		if err != nil || holes < 0 || holes >= 1 {
+			return grid, fmt.Errorf("holes probability should be at least 0 and below 1 but got %s", keyValue[1])
+		}
+		grid.Holes = holes
+	}
+	return grid, nil
+}
+
+// GenerateGrid builds the grid world in memory using the compass topology. Holes are chosen
+// with the random generator, cities left without neighbours stay on the map.
+func GenerateGrid(grid Grid, rng *rand.Rand) (WorldMap, error) {
+	if grid.WrapX && grid.Width < 2 || grid.WrapY && grid.Height < 2 {
+		return nil, fmt.Errorf("wrapping edges requires at least 2 cities across but the grid is %dx%d", grid.Width, grid.Height)
+	}
+	present := make([][]bool, grid.Height)
+	for row := range present {
+		present[row] = make([]bool, grid.Width)
+		for column := range present[row] {
+			present[row][column] = grid.Holes == 0 || rng.Float64() >= grid.Holes
+		}
+	}
+	worldMap := InitWorldMap()
+	for row := uint32(0); row < grid.Height; row++ {
+		for column := uint32(0); column < grid.Width; column++ {
+			if !present[row][column] {
+				continue
+			}
+			name := gridCity(row, column)
+			if err := worldMap.AddCity(name, nil); err != nil {
+				return nil, err
+			}
+			east, south := column+1, row+1
+			if grid.WrapX && east == grid.Width {
+				east = 0
+			}
+			if grid.WrapY && south == grid.Height {
+				south = 0
+			}
+			if east < grid.Width && present[row][east] {
+				if err := worldMap.AddRoad(name, "east", gridCity(row, east), RoadOptions{}); err != nil {
+					return nil, err
+				}
+			}
+			if south < grid.Height && present[south][column] {
+				if err := worldMap.AddRoad(name, "south", gridCity(south, column), RoadOptions{}); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return worldMap, nil
+}
+
+func gridCity(row uint32, column uint32) string {
+	return fmt.Sprintf("r%dc%d", row, column)
+}
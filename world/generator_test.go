@@ -0,0 +1,69 @@
+package world
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestGenerateGrid(t *testing.T) {
+	wm, err := GenerateGrid(Grid{Width: 3, Height: 2}, rand.New(rand.NewSource(0)))
+	assert.NilError(t, err)
+	assert.Equal(t, WriteMap(wm), "r0c0 east=r0c1 south=r1c0 \n"+
+		"r0c1 east=r0c2 west=r0c0 south=r1c1 \n"+
+		"r0c2 west=r0c1 south=r1c2 \n"+
+		"r1c0 east=r1c1 north=r0c0 \n"+
+		"r1c1 east=r1c2 north=r0c1 west=r1c0 \n"+
+		"r1c2 north=r0c2 west=r1c1 \n")
+}
+
+func TestGenerateTorusAndCylinder(t *testing.T) {
+	torus, err := GenerateGrid(Grid{Width: 4, Height: 3, WrapX: true, WrapY: true}, rand.New(rand.NewSource(0)))
+	assert.NilError(t, err)
+	assert.Assert(t, len(torus.GetCities()) == 12)
+	for _, city := range torus.GetCities() {
+		assert.Assert(t, len(city.GetDirections()) == 4, city.Name)
+	}
+	assert.Assert(t, torus.GetCities()["r0c3"].Roads["east"].To.Name == "r0c0")
+	assert.Assert(t, torus.GetCities()["r0c0"].Roads["north"].To.Name == "r2c0")
+
+	cylinder, err := GenerateGrid(Grid{Width: 4, Height: 3, WrapX: true}, rand.New(rand.NewSource(0)))
+	assert.NilError(t, err)
+	assert.Assert(t, len(cylinder.GetCities()["r0c0"].GetDirections()) == 3)
+	assert.Assert(t, cylinder.GetCities()["r0c0"].Roads["west"].To.Name == "r0c3")
+
+	_, err = GenerateGrid(Grid{Width: 1, Height: 3, WrapX: true}, rand.New(rand.NewSource(0)))
+	assert.Error(t, err, "wrapping edges requires at least 2 cities across but the grid is 1x3")
+}
+
+func TestGenerateGridWithHoles(t *testing.T) {
+	wm, err := GenerateGrid(Grid{Width: 10, Height: 10, Holes: 0.3}, rand.New(rand.NewSource(1)))
+	assert.NilError(t, err)
+	cities := len(wm.GetCities())
+	assert.Assert(t, cities > 50 && cities < 90, cities)
+	// the generated map is a valid input
+	parsed, err := ParseMap(strings.Split(WriteMap(wm), "\n"), CompassTopology)
+	assert.NilError(t, err)
+	assert.Equal(t, WriteMap(parsed), WriteMap(wm))
+}
+
+func TestParseGrid(t *testing.T) {
+	grid, err := ParseGrid("torus:20x10,holes:0.1")
+	assert.NilError(t, err)
+	assert.Assert(t, grid == Grid{Width: 20, Height: 10, WrapX: true, WrapY: true, Holes: 0.1})
+	grid, err = ParseGrid("cylinder:5x1")
+	assert.NilError(t, err)
+	assert.Assert(t, grid == Grid{Width: 5, Height: 1, WrapX: true})
+	_, err = ParseGrid("sphere:5x5")
+	assert.Error(t, err, "expected grid, cylinder or torus:<width>x<height> but got sphere:5x5")
+	_, err = ParseGrid("grid:5")
+	assert.Error(t, err, "expected <width>x<height> but got 5")
+	_, err = ParseGrid("grid:0x5")
+	assert.Error(t, err, "grid size should be a positive integer but got 0")
+	_, err = ParseGrid("grid:5x5,holes:1")
+	assert.Error(t, err, "holes probability should be at least 0 and below 1 but got 1")
+	_, err = ParseGrid("grid:5x5,seed:1")
+	assert.Error(t, err, "unknown grid option seed:1")
+}
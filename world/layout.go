@@ -0,0 +1,334 @@
+package world
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Point is a position of a city on the grid, Y grows to the south.
+type Point struct {
+	X int
+	Y int
+}
+
+// gridOffsets keeps the grid step of every direction which can be laid out on a grid.
+var gridOffsets = map[string]Point{
+	"east":      {1, 0},
+	"west":      {-1, 0},
+	"north":     {0, -1},
+	"south":     {0, 1},
+	"northeast": {1, -1},
+	"northwest": {-1, -1},
+	"southeast": {1, 1},
+	"southwest": {-1, 1},
+}
+
+// hexOffsets lays out the hexagonal grid in axial coordinates, so going northeast and then
+// southeast is the same as going east. North-western and south-eastern roads become vertical.
+var hexOffsets = map[string]Point{
+	"east":      {1, 0},
+	"west":      {-1, 0},
+	"northeast": {1, -1},
+	"southwest": {-1, 1},
+	"northwest": {0, -1},
+	"southeast": {0, 1},
+}
+
+// offsetsOf returns grid steps of directions of the topology.
+func offsetsOf(topology *Topology) map[string]Point {
+	if topology == HexTopology {
+		return hexOffsets
+	}
+	return gridOffsets
+}
+
+// gridEdge is a step from one city to its neighbour on the grid.
+type gridEdge struct {
+	from      string
+	to        string
+	direction string
+	offset    Point
+}
+
+// link identifies the geometric relation between two cities regardless of the side it is seen from,
+// so a two-way road and both its halves are the same link.
+func (e gridEdge) link() gridEdge {
+	if e.from > e.to {
+		return gridEdge{from: e.to, to: e.from, offset: Point{-e.offset.X, -e.offset.Y}}
+	}
+	return gridEdge{from: e.from, to: e.to, offset: e.offset}
+}
+
+// Contradiction is a route of roads which contradicts the grid when every road is one step
+// in its direction: either a cycle which doesn't return to its first city, e.g. going north, east,
+// south and east, or a route which returns into the cell of its first city without a cycle,
+// e.g. going north, east, south and west to another city.
+type Contradiction struct {
+	// Cities along the route, the first city is repeated at the end of a cycle.
+	Cities []string
+	// Directions of steps between the cities, roads passed backwards are named by the opposite direction.
+	Directions []string
+}
+
+func (c Contradiction) Error() string {
+	var cycle strings.Builder
+	cycle.WriteString(c.Cities[0])
+	for i, direction := range c.Directions {
+		cycle.WriteString(" " + direction + " " + c.Cities[i+1])
+	}
+	if c.Cities[0] == c.Cities[len(c.Cities)-1] {
+		return fmt.Sprintf("going %s doesn't return to %s on the grid", cycle.String(), c.Cities[0])
+	}
+	return fmt.Sprintf("going %s leads into the cell of %s on the grid", cycle.String(), c.Cities[0])
+}
+
+// Geometry keeps grid positions of cities and ruins inferred from directions of roads.
+type Geometry struct {
+	// Positions of all cities and ruins. Connected parts of the world are placed side by side from west
+	// to east in order of their alphabetically first cities. Cities are placed by roads found first
+	// by breadth-first search from that city, so roads contradicting them are not taken into account.
+	Positions map[string]Point
+	// Contradictions found for every road which doesn't agree with the positions
+	// and every pair of cities placed into the same cell.
+	Contradictions []Contradiction
+	// components keeps the connected part of the world of every city
+	components map[string]int
+	topology   *Topology
+}
+
+// InferGeometry lays out cities and ruins on a grid by their compass relations, every road
+// is one step long in its direction. One-way and closed roads are taken into account, portals
+// are ignored. Contradicting roads are reported in the result, while directions which can't be
+// laid out on a grid, e.g. up, cause an error.
+func InferGeometry(worldMap WorldMap) (Geometry, error) {
+	topology := worldMap.GetTopology()
+	edges, err := gridEdges(worldMap, offsetsOf(topology))
+	if err != nil {
+		return Geometry{}, err
+	}
+	names := make([]string, 0, len(edges))
+	for name := range edges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	geometry := Geometry{Positions: make(map[string]Point, len(names)), components: make(map[string]int, len(names)), topology: topology}
+	contradicted := make(map[gridEdge]bool)
+	left := 0
+	for _, first := range names {
+		if _, placed := geometry.Positions[first]; placed {
+			continue
+		}
+		component := map[string]Point{first: {0, 0}}
+		parents := make(map[string]gridEdge)
+		queue := []string{first}
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+			for _, edge := range edges[name] {
+				position := Point{component[name].X + edge.offset.X, component[name].Y + edge.offset.Y}
+				if known, placed := component[edge.to]; placed {
+					if known != position && !contradicted[edge.link()] {
+						contradicted[edge.link()] = true
+						cycle := routeOf(edge.to, edge.from, parents, topology)
+						cycle.Cities = append(cycle.Cities, edge.to)
+						cycle.Directions = append(cycle.Directions, edge.direction)
+						geometry.Contradictions = append(geometry.Contradictions, cycle)
+					}
+					continue
+				}
+				component[edge.to] = position
+				parents[edge.to] = edge
+				queue = append(queue, edge.to)
+			}
+		}
+		geometry.Contradictions = append(geometry.Contradictions, overlapsOf(component, parents, topology)...)
+		minX, maxX, minY := 0, 0, 0
+		for _, position := range component {
+			if position.X < minX {
+				minX = position.X
+			}
+			if position.X > maxX {
+				maxX = position.X
+			}
+			if position.Y < minY {
+				minY = position.Y
+			}
+		}
+		for name, position := range component {
+			geometry.Positions[name] = Point{position.X - minX + left, position.Y - minY}
+			geometry.components[name] = left
+		}
+		left += maxX - minX + 2
+	}
+	return geometry, nil
+}
+
+// routeOf returns the route between two cities of the search tree through their common ancestor.
+func routeOf(from string, to string, parents map[string]gridEdge, topology *Topology) Contradiction {
+	ancestors := map[string]bool{to: true}
+	for city := to; parents[city].to != ""; city = parents[city].from {
+		ancestors[parents[city].from] = true
+	}
+	route := Contradiction{Cities: []string{from}}
+	top := from
+	for ; !ancestors[top]; top = parents[top].from {
+		opposite, _ := topology.Opposite(parents[top].direction)
+		route.Cities = append(route.Cities, parents[top].from)
+		route.Directions = append(route.Directions, opposite)
+	}
+	down := make([]gridEdge, 0)
+	for city := to; city != top; city = parents[city].from {
+		down = append([]gridEdge{parents[city]}, down...)
+	}
+	for _, edge := range down {
+		route.Cities = append(route.Cities, edge.to)
+		route.Directions = append(route.Directions, edge.direction)
+	}
+	return route
+}
+
+// overlapsOf returns routes between cities of the component placed into the same cell.
+func overlapsOf(component map[string]Point, parents map[string]gridEdge, topology *Topology) []Contradiction {
+	names := make([]string, 0, len(component))
+	for name := range component {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	overlaps := make([]Contradiction, 0)
+	occupied := make(map[Point]string, len(names))
+	for _, name := range names {
+		if other, taken := occupied[component[name]]; taken {
+			overlaps = append(overlaps, routeOf(other, name, parents, topology))
+			continue
+		}
+		occupied[component[name]] = name
+	}
+	return overlaps
+}
+
+// Distance returns the least possible amount of roads between two cities according to their positions,
+// which makes it a heuristic for route search when the geometry has no contradictions. Distance is
+// measured in steps of the topology: Manhattan distance for the compass, Chebyshev distance when
+// diagonal directions are allowed and the hexagonal distance for hexagonal maps.
+// It returns false if any of the cities is not laid out or the cities are in different connected parts
+// of the laid-out map, which are joined by open, closed and one-way roads alike.
+func (g Geometry) Distance(from string, to string) (int, bool) {
+	a, ok := g.Positions[from]
+	b, ok2 := g.Positions[to]
+	if !ok || !ok2 || g.components[from] != g.components[to] {
+		return 0, false
+	}
+	dx, dy := abs(a.X-b.X), abs(a.Y-b.Y)
+	switch {
+	case g.topology == HexTopology:
+		// axial coordinates, the third one is -x-y
+		return (dx + dy + abs(a.X-b.X+a.Y-b.Y)) / 2, true
+	case g.diagonal():
+		if dx > dy {
+			return dx, true
+		}
+		return dy, true
+	}
+	return dx + dy, true
+}
+
+// diagonal checks whether the topology has directions changing both coordinates at once.
+func (g Geometry) diagonal() bool {
+	if g.topology == nil {
+		return false
+	}
+	offsets := offsetsOf(g.topology)
+	for _, direction := range g.topology.Directions {
+		if offset, ok := offsets[direction]; ok && offset.X != 0 && offset.Y != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// Layout assigns grid positions to cities and ruins with InferGeometry. It returns the first
+// contradiction as an error or an error if the roads use directions which can't be laid out on a grid.
+func Layout(worldMap WorldMap) (map[string]Point, error) {
+	geometry, err := InferGeometry(worldMap)
+	if err != nil {
+		return nil, err
+	}
+	if len(geometry.Contradictions) > 0 {
+		return nil, geometry.Contradictions[0]
+	}
+	return geometry.Positions, nil
+}
+
+// gridEdges returns steps from every city and ruin to its neighbours in both directions of every road,
+// so one-way roads are laid out as well. Edges are sorted to make the layout reproducible.
+func gridEdges(worldMap WorldMap, offsets map[string]Point) (map[string][]gridEdge, error) {
+	topology := worldMap.GetTopology()
+	edges := make(map[string][]gridEdge)
+	addLink := func(from string, direction string, to string) error {
+		offset, ok := offsets[direction]
+		if !ok {
+			return fmt.Errorf("direction %s can't be laid out on a grid", direction)
+		}
+		opposite, _ := topology.Opposite(direction)
+		edges[from] = append(edges[from], gridEdge{from: from, to: to, direction: direction, offset: offset})
+		edges[to] = append(edges[to], gridEdge{from: to, to: from, direction: opposite, offset: Point{-offset.X, -offset.Y}})
+		return nil
+	}
+	cities := worldMap.GetCities()
+	names := make([]string, 0, len(cities))
+	for name := range cities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		edges[name] = nil
+		for _, direction := range topology.Directions {
+			road := cities[name].Roads[direction]
+			if road == nil {
+				road = cities[name].Closed[direction]
+			}
+			if road == nil {
+				continue
+			}
+			if err := addLink(name, direction, road.To.Name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	ruins := worldMap.GetRuins()
+	names = names[:0]
+	for name := range ruins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := edges[name]; !ok {
+			edges[name] = nil
+		}
+		for _, link := range ruins[name].Links {
+			if link.Portal {
+				continue
+			}
+			if err := addLink(link.From, link.Direction, link.To); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, list := range edges {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].to != list[j].to {
+				return list[i].to < list[j].to
+			}
+			return list[i].direction < list[j].direction
+		})
+	}
+	return edges, nil
+}
@@ -0,0 +1,92 @@
+package world
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLayout(t *testing.T) {
+	wm := InitWorldMapWithTopology(Compass8Topology)
+	wm.AddCity("A", map[string]string{"east": "B", "southeast": "C"})
+	wm.AddCity("B", map[string]string{"south": "C"})
+	wm.AddCity("D", nil)
+	positions, err := Layout(wm)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, positions, map[string]Point{"A": {0, 0}, "B": {1, 0}, "C": {1, 1}, "D": {3, 0}})
+	geometry, err := InferGeometry(wm)
+	assert.NilError(t, err)
+	distance, ok := geometry.Distance("A", "C")
+	assert.Assert(t, ok && distance == 1)
+	// D can't be reached from A at all
+	_, ok = geometry.Distance("A", "D")
+	assert.Assert(t, !ok)
+
+	wm.AddRoad("C", "west", "A", RoadOptions{})
+	_, err = Layout(wm)
+	assert.Error(t, err, "going C west A southeast C doesn't return to C on the grid")
+
+	wm = InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B", "south": "C"})
+	wm.AddCity("C", map[string]string{"east": "D"})
+	wm.AddCity("D", map[string]string{"north": "E"})
+	_, err = Layout(wm)
+	assert.Error(t, err, "going B west A south C east D north E leads into the cell of B on the grid")
+
+	wm = InitWorldMapWithTopology(CubeTopology)
+	wm.AddCity("A", map[string]string{"up": "B"})
+	_, err = Layout(wm)
+	assert.Error(t, err, "direction up can't be laid out on a grid")
+}
+
+func TestInferGeometry(t *testing.T) {
+	// going north, east, south and west doesn't return home
+	wm := InitWorldMap()
+	wm.AddCity("Home", map[string]string{"north": "A"})
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddCity("B", map[string]string{"south": "C"})
+	wm.AddCity("C", map[string]string{"west": "D"})
+	geometry, err := InferGeometry(wm)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, geometry.Positions, map[string]Point{"A": {0, 0}, "B": {1, 0}, "C": {1, 1}, "D": {0, 1}, "Home": {0, 1}})
+	assert.DeepEqual(t, geometry.Contradictions, []Contradiction{{
+		Cities:     []string{"D", "C", "B", "A", "Home"},
+		Directions: []string{"east", "north", "west", "south"},
+	}})
+	// compass roads don't go diagonally
+	distance, ok := geometry.Distance("A", "C")
+	assert.Assert(t, ok && distance == 2)
+	_, ok = geometry.Distance("A", "Nowhere")
+	assert.Assert(t, !ok)
+
+	// the one-way road back home goes in the wrong direction
+	wm.AddRoad("C", "east", "Home", RoadOptions{OneWay: true})
+	geometry, err = InferGeometry(wm)
+	assert.NilError(t, err)
+	assert.Assert(t, len(geometry.Contradictions) == 2)
+	assert.Error(t, geometry.Contradictions[0], "going Home north A east B south C east Home doesn't return to Home on the grid")
+
+	// both halves of a two-way road are reported once
+	wm = InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddCity("B", map[string]string{"east": "A"})
+	geometry, err = InferGeometry(wm)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, geometry.Contradictions, []Contradiction{{Cities: []string{"B", "A", "B"}, Directions: []string{"west", "west"}}})
+}
+
+func TestInferGeometryHexTopology(t *testing.T) {
+	// going northeast and then southeast is the same as going east
+	wm := InitWorldMapWithTopology(HexTopology)
+	wm.AddCity("A", map[string]string{"northeast": "B", "east": "C"})
+	wm.AddCity("B", map[string]string{"southeast": "C"})
+	geometry, err := InferGeometry(wm)
+	assert.NilError(t, err)
+	assert.Assert(t, len(geometry.Contradictions) == 0)
+	assert.DeepEqual(t, geometry.Positions, map[string]Point{"A": {0, 1}, "B": {1, 0}, "C": {1, 1}})
+	distance, ok := geometry.Distance("A", "B")
+	assert.Assert(t, ok && distance == 1)
+	output, err := Render(wm)
+	assert.NilError(t, err)
+	assert.Equal(t, output, "    B\n    |\nA---C\n")
+}
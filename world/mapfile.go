@@ -0,0 +1,274 @@
+package world
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseMap creates a world map from lines of the map file. Empty lines
+// and lines starting with # are ignored.
+func ParseMap(lines []string, topology *Topology) (WorldMap, error) {
+	/*
+		Sample file data:
+		------------------
+		Foo north=Bar west=Baz south=Qu-ux
+		Bar south=Foo west=Bee east>Ferry:weight=0.5 ~Stargate=Qu-ux:chance=0.3 @population=5000
+	*/
+	worldMap := InitWorldMapWithTopology(topology)
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// expect every line data is separated by spaces
+		words := strings.Split(line, " ")
+		// first word is always a city name (shouldn't contain spaces)
+		newCity := words[0]
+		if err := worldMap.AddCity(newCity, nil); err != nil {
+			return nil, err
+		}
+		// expect direction=city or direction>city pairs, one pair for every direction of the topology,
+		// ~portal=city or ~portal>city portals and @key=value city attributes
+		for i := 1; i < len(words); i++ {
+			if strings.HasPrefix(words[i], "~") {
+				name, city, options, err := parsePortal(words[i][1:])
+				if err != nil {
+					return nil, err
+				}
+				if err := worldMap.AddPortal(newCity, name, city, options); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if strings.HasPrefix(words[i], "@") {
+				attribute := strings.Split(words[i][1:], "=")
+				if len(attribute) != 2 || attribute[0] == "" {
+					return nil, fmt.Errorf("expected @key=value format but got %s", words[i])
+				}
+				worldMap.SetMetadata(newCity, attribute[0], attribute[1])
+				continue
+			}
+			direction, city, options, err := parseRoad(words[i])
+			if err != nil {
+				return nil, err
+			}
+			if declared, err := declaredRoad(worldMap, newCity, direction, city, options); err != nil || declared {
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if err := worldMap.AddRoad(newCity, direction, city, options); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return worldMap, nil
+}
+
+// parseRoad parses a single road description. Two-way roads are written as direction=city,
+// one-way roads as direction>city. Road attributes may follow the city name separated
+// by colons, e.g. north=Bar:weight=2.5:length=3:closed=true. City names are assumed to contain none of =, > and :.
+func parseRoad(word string) (string, string, RoadOptions, error) {
+	options := RoadOptions{}
+	separator := strings.IndexAny(word, "=>")
+	if separator < 0 {
+		return "", "", options, fmt.Errorf("expected city1=city2 format but got %s", word)
+	}
+	options.OneWay = word[separator] == '>'
+	direction := word[:separator]
+	parts := strings.Split(word[separator+1:], ":")
+	city := parts[0]
+	if direction == "" || city == "" || strings.ContainsAny(city, "=>") {
+		return "", "", options, fmt.Errorf("expected city1=city2 format but got %s", word)
+	}
+	for _, attribute := range parts[1:] {
+		keyValue := strings.Split(attribute, "=")
+		if len(keyValue) != 2 {
+			return "", "", options, fmt.Errorf("expected key=value road attribute but got %s", attribute)
+		}
+		switch keyValue[0] {
+		case "weight":
+			weight, err := strconv.ParseFloat(keyValue[1], 64)
+			if err != nil || weight <= 0 {
+				return "", "", options, fmt.Errorf("road weight should be a positive number but got %s", keyValue[1])
+			}
+			options.Weight = weight
+		case "closed":
+			closed, err := strconv.ParseBool(keyValue[1])
+			if err != nil {
+				return "", "", options, fmt.Errorf("road closed attribute should be true or false but got %s", keyValue[1])
+			}
+			options.Closed = closed
+		case "length":
+			length, err := strconv.ParseUint(keyValue[1], 10, 32)
+			if err != nil || length == 0 {
+				return "", "", options, fmt.Errorf("road length should be a positive integer but got %s", keyValue[1])
+			}
+			options.Length = uint32(length)
+		default:
+			return "", "", options, fmt.Errorf("unknown road attribute %s", keyValue[0])
+		}
+	}
+	return direction, city, options, nil
+}
+
+// declaredRoad checks the road against the roads declared earlier, usually the two-way road
+// written on the line of the neighbour. It returns true if the road already exists, so options
+// of the earlier declaration are kept when the reciprocal road is written without attributes.
+// It returns an error if the declarations don't agree with each other.
+func declaredRoad(worldMap WorldMap, from string, direction string, to string, options RoadOptions) (bool, error) {
+	cities := worldMap.GetCities()
+	opposite, ok := worldMap.GetTopology().Opposite(direction)
+	if !ok {
+		// AddRoad reports the wrong direction
+		return false, nil
+	}
+	forward, forwardClosed := roadTo(cities[from], direction, to)
+	backward, backwardClosed := roadTo(cities[to], opposite, from)
+	if forward == nil && (options.OneWay || backward == nil) {
+		return false, nil
+	}
+	if options == (RoadOptions{}) && forward != nil && backward != nil {
+		return true, nil
+	}
+	if options.Weight == 0 {
+		options.Weight = 1
+	}
+	if options.Length == 0 {
+		options.Length = 1
+	}
+	matches := func(road *Road, closed bool) bool {
+		return road != nil && road.Weight == options.Weight && road.Length == options.Length && closed == options.Closed
+	}
+	if matches(forward, forwardClosed) && (options.OneWay || matches(backward, backwardClosed)) {
+		return true, nil
+	}
+	return false, fmt.Errorf("road %s from %s to %s doesn't agree with its earlier declaration", direction, from, to)
+}
+
+// roadTo returns the open or closed road of the city in given direction if it leads to the neighbour.
+func roadTo(city *City, direction string, neighbour string) (*Road, bool) {
+	if city == nil {
+		return nil, false
+	}
+	if road := city.Roads[direction]; road != nil && road.To.Name == neighbour {
+		return road, false
+	}
+	if road := city.Closed[direction]; road != nil && road.To.Name == neighbour {
+		return road, true
+	}
+	return nil, false
+}
+
+// parsePortal parses a portal description without the leading ~. Two-way portals are written
+// as name=city, one-way portals as name>city, the only attribute is chance, e.g. Stargate=Bar:chance=0.3.
+func parsePortal(word string) (string, string, PortalOptions, error) {
+	options := PortalOptions{}
+	separator := strings.IndexAny(word, "=>")
+	if separator <= 0 {
+		return "", "", options, fmt.Errorf("expected ~portal=city format but got ~%s", word)
+	}
+	options.OneWay = word[separator] == '>'
+	parts := strings.Split(word[separator+1:], ":")
+	if parts[0] == "" || strings.ContainsAny(parts[0], "=>") {
+		return "", "", options, fmt.Errorf("expected ~portal=city format but got ~%s", word)
+	}
+	for _, attribute := range parts[1:] {
+		keyValue := strings.Split(attribute, "=")
+		if len(keyValue) != 2 || keyValue[0] != "chance" {
+			return "", "", options, fmt.Errorf("expected chance=<probability> portal attribute but got %s", attribute)
+		}
+		chance, err := strconv.ParseFloat(keyValue[1], 64)
+		if err != nil || chance <= 0 || chance > 1 {
+			return "", "", options, fmt.Errorf("portal chance should be above 0 and up to 1 but got %s", keyValue[1])
+		}
+		options.Chance = chance
+	}
+	return word[:separator], parts[0], options, nil
+}
+
+// WriteMap returns the world map in the same format as input data, one city per line
+// in alphabetical order. Direction, weight and closures of roads as well as portals are preserved
+// so the output can be used as an input again.
+func WriteMap(worldMap WorldMap) string {
+	result := ""
+	cities := worldMap.GetCities()
+	names := make([]string, 0, len(cities))
+	for name := range cities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		city := cities[name]
+		cityOutput := fmt.Sprintf("%s ", name)
+		for _, dir := range allDirections(city) {
+			road, closed := city.Roads[dir], false
+			if road == nil {
+				road, closed = city.Closed[dir], true
+			}
+			separator := ">"
+			if city.IsTwoWay(dir) {
+				separator = "="
+			}
+			cityOutput += fmt.Sprintf("%s%s%s", dir, separator, road.To.Name)
+			if road.Weight != 1 {
+				cityOutput += fmt.Sprintf(":weight=%g", road.Weight)
+			}
+			if road.Length > 1 {
+				cityOutput += fmt.Sprintf(":length=%d", road.Length)
+			}
+			if closed {
+				cityOutput += ":closed=true"
+			}
+			cityOutput += " "
+		}
+		for _, portalName := range city.PortalNames() {
+			portal := city.Portals[portalName]
+			separator := ">"
+			if city.IsTwoWayPortal(portalName) {
+				separator = "="
+			}
+			cityOutput += fmt.Sprintf("~%s%s%s", portalName, separator, portal.To.Name)
+			if portal.Chance != defaultPortalChance {
+				cityOutput += fmt.Sprintf(":chance=%g", portal.Chance)
+			}
+			cityOutput += " "
+		}
+		keys := make([]string, 0, len(city.Metadata))
+		for key := range city.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			cityOutput += fmt.Sprintf("@%s=%s ", key, city.Metadata[key])
+		}
+		result += cityOutput + "\n"
+	}
+	return result
+}
+
+// allDirections returns directions of both open and closed roads of the city
+// in the order of its topology.
+func allDirections(city *City) []string {
+	directions := make([]string, 0, len(city.Roads)+len(city.Closed))
+	if city.Topology == nil {
+		for direction := range city.Roads {
+			directions = append(directions, direction)
+		}
+		for direction := range city.Closed {
+			directions = append(directions, direction)
+		}
+		sort.Strings(directions)
+		return directions
+	}
+	for _, direction := range city.Topology.Directions {
+		if city.Roads[direction] != nil || city.Closed[direction] != nil {
+			directions = append(directions, direction)
+		}
+	}
+	return directions
+}
@@ -0,0 +1,83 @@
+package world
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseMap(t *testing.T) {
+	wm, err := ParseMap([]string{
+		"# sample map",
+		"Foo north=Bar west=Baz south=Qu-ux",
+		"",
+		"Bar south=Foo west=Bee east>Ferry:weight=0.5:length=2 @population=5000",
+	}, CompassTopology)
+	assert.NilError(t, err)
+	assert.Assert(t, len(wm.GetCities()) == 6)
+	assert.Assert(t, wm.GetCities()["Qu-ux"].Roads["north"].To.Name == "Foo")
+	ferry := wm.GetCities()["Bar"].Roads["east"]
+	assert.Assert(t, ferry.To.Name == "Ferry" && ferry.Weight == 0.5 && ferry.Length == 2)
+	assert.Assert(t, len(wm.GetCities()["Ferry"].Roads) == 0)
+	assert.Assert(t, wm.GetCities()["Bar"].Metadata["population"] == "5000")
+}
+
+func TestParseMapReciprocalRoads(t *testing.T) {
+	// attributes written on one side of a two-way road are kept by the plain reciprocal road
+	wm, err := ParseMap([]string{
+		"A east=B:weight=3:length=2 south=C:closed=true",
+		"B west=A",
+		"C north=A",
+	}, CompassTopology)
+	assert.NilError(t, err)
+	assert.Equal(t, WriteMap(wm), "A east=B:weight=3:length=2 south=C:closed=true \n"+
+		"B west=A:weight=3:length=2 \n"+
+		"C north=A:closed=true \n")
+	_, err = ParseMap([]string{"A east=B:weight=3", "B west=A:weight=2"}, CompassTopology)
+	assert.Error(t, err, "road west from B to A doesn't agree with its earlier declaration")
+	_, err = ParseMap([]string{"A east>B:weight=3", "B west=A"}, CompassTopology)
+	assert.Error(t, err, "road west from B to A doesn't agree with its earlier declaration")
+	// opposite one-way roads are still allowed
+	wm, err = ParseMap([]string{"A east>B:weight=3", "B west>A"}, CompassTopology)
+	assert.NilError(t, err)
+	assert.Assert(t, wm.GetCities()["A"].Roads["east"].Weight == 3 && wm.GetCities()["B"].Roads["west"].Weight == 1)
+}
+
+func TestParseMapErrors(t *testing.T) {
+	_, err := ParseMap([]string{"Foo north:Bar"}, CompassTopology)
+	assert.Error(t, err, "expected city1=city2 format but got north:Bar")
+	_, err = ParseMap([]string{"Foo up=Bar"}, CompassTopology)
+	assert.Error(t, err, "wrong direction up")
+	_, err = ParseMap([]string{"Foo north=Bar:weight=-1"}, CompassTopology)
+	assert.Error(t, err, "road weight should be a positive number but got -1")
+	_, err = ParseMap([]string{"Foo north=Bar:length=0"}, CompassTopology)
+	assert.Error(t, err, "road length should be a positive integer but got 0")
+	_, err = ParseMap([]string{"Foo north=Bar:speed=1"}, CompassTopology)
+	assert.Error(t, err, "unknown road attribute speed")
+	_, err = ParseMap([]string{"Foo north=Bar:closed=maybe"}, CompassTopology)
+	assert.Error(t, err, "road closed attribute should be true or false but got maybe")
+	_, err = ParseMap([]string{"Foo ~=Bar"}, CompassTopology)
+	assert.Error(t, err, "expected ~portal=city format but got ~=Bar")
+	_, err = ParseMap([]string{"Foo ~Gate=Bar:chance=0"}, CompassTopology)
+	assert.Error(t, err, "portal chance should be above 0 and up to 1 but got 0")
+	_, err = ParseMap([]string{"Foo ~Gate=Bar:weight=2"}, CompassTopology)
+	assert.Error(t, err, "expected chance=<probability> portal attribute but got weight=2")
+	_, err = ParseMap([]string{"Foo @population"}, CompassTopology)
+	assert.Error(t, err, "expected @key=value format but got @population")
+}
+
+func TestWriteMapRoundTrip(t *testing.T) {
+	input := []string{
+		"Bar east>Ferry:weight=0.5:length=2 south=Foo ~Gate=Moon:chance=0.2 @population=5000 ",
+		"Ferry ~Wormhole>Foo ",
+		"Foo north=Bar up=Moon:length=3:closed=true ",
+		"Moon down=Foo:length=3:closed=true west>Foo:closed=true ",
+	}
+	wm, err := ParseMap(input, CubeTopology)
+	assert.NilError(t, err)
+	output := WriteMap(wm)
+	assert.Equal(t, output, "Bar east>Ferry:weight=0.5:length=2 south=Foo ~Gate=Moon:chance=0.2 @population=5000 \n"+
+		"Ferry ~Wormhole>Foo \n"+
+		"Foo north=Bar up=Moon:length=3:closed=true \n"+
+		"Moon west>Foo:closed=true down=Foo:length=3:closed=true ~Gate=Bar:chance=0.2 \n")
+}
@@ -50,21 +50,67 @@ func (mr *MockWorldMapMockRecorder) AddAlien(alien interface{}) *gomock.Call {
 }
 
 // AddCity mocks base method.
-func (m *MockWorldMap) AddCity(name, east, north, west, south string) {
+func (m *MockWorldMap) AddCity(name string, roads map[string]string) error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "AddCity", name, east, north, west, south)
+	ret := m.ctrl.Call(m, "AddCity", name, roads)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // AddCity indicates an expected call of AddCity.
-func (mr *MockWorldMapMockRecorder) AddCity(name, east, north, west, south interface{}) *gomock.Call {
+func (mr *MockWorldMapMockRecorder) AddCity(name, roads interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCity", reflect.TypeOf((*MockWorldMap)(nil).AddCity), name, roads)
+}
+
+// AddPortal mocks base method.
+func (m *MockWorldMap) AddPortal(from, name, to string, options world.PortalOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPortal", from, name, to, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPortal indicates an expected call of AddPortal.
+func (mr *MockWorldMapMockRecorder) AddPortal(from, name, to, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPortal", reflect.TypeOf((*MockWorldMap)(nil).AddPortal), from, name, to, options)
+}
+
+// AddRoad mocks base method.
+func (m *MockWorldMap) AddRoad(from, direction, to string, options world.RoadOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRoad", from, direction, to, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRoad indicates an expected call of AddRoad.
+func (mr *MockWorldMapMockRecorder) AddRoad(from, direction, to, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRoad", reflect.TypeOf((*MockWorldMap)(nil).AddRoad), from, direction, to, options)
+}
+
+// CloseRoad mocks base method.
+func (m *MockWorldMap) CloseRoad(from, direction string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseRoad", from, direction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseRoad indicates an expected call of CloseRoad.
+func (mr *MockWorldMapMockRecorder) CloseRoad(from, direction interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCity", reflect.TypeOf((*MockWorldMap)(nil).AddCity), name, east, north, west, south)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseRoad", reflect.TypeOf((*MockWorldMap)(nil).CloseRoad), from, direction)
 }
 
 // DestroyCity mocks base method.
-func (m *MockWorldMap) DestroyCity(cityToDestroy string) {
+func (m *MockWorldMap) DestroyCity(cityToDestroy string) []string {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "DestroyCity", cityToDestroy)
+	ret := m.ctrl.Call(m, "DestroyCity", cityToDestroy)
+	ret0, _ := ret[0].([]string)
+	return ret0
 }
 
 // DestroyCity indicates an expected call of DestroyCity.
@@ -73,6 +119,20 @@ func (mr *MockWorldMapMockRecorder) DestroyCity(cityToDestroy interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyCity", reflect.TypeOf((*MockWorldMap)(nil).DestroyCity), cityToDestroy)
 }
 
+// DestroyRoad mocks base method.
+func (m *MockWorldMap) DestroyRoad(from, direction string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DestroyRoad", from, direction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DestroyRoad indicates an expected call of DestroyRoad.
+func (mr *MockWorldMapMockRecorder) DestroyRoad(from, direction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyRoad", reflect.TypeOf((*MockWorldMap)(nil).DestroyRoad), from, direction)
+}
+
 // GetAliens mocks base method.
 func (m *MockWorldMap) GetAliens() map[string]*world.Alien {
 	m.ctrl.T.Helper()
@@ -101,10 +161,40 @@ func (mr *MockWorldMapMockRecorder) GetCities() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCities", reflect.TypeOf((*MockWorldMap)(nil).GetCities))
 }
 
+// GetRuins mocks base method.
+func (m *MockWorldMap) GetRuins() map[string]*world.Ruin {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRuins")
+	ret0, _ := ret[0].(map[string]*world.Ruin)
+	return ret0
+}
+
+// GetRuins indicates an expected call of GetRuins.
+func (mr *MockWorldMapMockRecorder) GetRuins() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRuins", reflect.TypeOf((*MockWorldMap)(nil).GetRuins))
+}
+
+// GetTopology mocks base method.
+func (m *MockWorldMap) GetTopology() *world.Topology {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTopology")
+	ret0, _ := ret[0].(*world.Topology)
+	return ret0
+}
+
+// GetTopology indicates an expected call of GetTopology.
+func (mr *MockWorldMapMockRecorder) GetTopology() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopology", reflect.TypeOf((*MockWorldMap)(nil).GetTopology))
+}
+
 // MoveAlien mocks base method.
-func (m *MockWorldMap) MoveAlien(alien *world.Alien, rng *rand.Rand) {
+func (m *MockWorldMap) MoveAlien(alien *world.Alien, rng *rand.Rand) bool {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "MoveAlien", alien, rng)
+	ret := m.ctrl.Call(m, "MoveAlien", alien, rng)
+	ret0, _ := ret[0].(bool)
+	return ret0
 }
 
 // MoveAlien indicates an expected call of MoveAlien.
@@ -112,3 +202,85 @@ func (mr *MockWorldMapMockRecorder) MoveAlien(alien, rng interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveAlien", reflect.TypeOf((*MockWorldMap)(nil).MoveAlien), alien, rng)
 }
+
+// MoveAlienAlong mocks base method.
+func (m *MockWorldMap) MoveAlienAlong(alien *world.Alien, direction string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveAlienAlong", alien, direction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveAlienAlong indicates an expected call of MoveAlienAlong.
+func (mr *MockWorldMapMockRecorder) MoveAlienAlong(alien, direction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveAlienAlong", reflect.TypeOf((*MockWorldMap)(nil).MoveAlienAlong), alien, direction)
+}
+
+// RebuildCity mocks base method.
+func (m *MockWorldMap) RebuildCity(name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebuildCity", name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RebuildCity indicates an expected call of RebuildCity.
+func (mr *MockWorldMapMockRecorder) RebuildCity(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebuildCity", reflect.TypeOf((*MockWorldMap)(nil).RebuildCity), name)
+}
+
+// RemoveAlien mocks base method.
+func (m *MockWorldMap) RemoveAlien(name string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RemoveAlien", name)
+}
+
+// RemoveAlien indicates an expected call of RemoveAlien.
+func (mr *MockWorldMapMockRecorder) RemoveAlien(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAlien", reflect.TypeOf((*MockWorldMap)(nil).RemoveAlien), name)
+}
+
+// ReopenRoad mocks base method.
+func (m *MockWorldMap) ReopenRoad(from, direction string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReopenRoad", from, direction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReopenRoad indicates an expected call of ReopenRoad.
+func (mr *MockWorldMapMockRecorder) ReopenRoad(from, direction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReopenRoad", reflect.TypeOf((*MockWorldMap)(nil).ReopenRoad), from, direction)
+}
+
+// RuinCity mocks base method.
+func (m *MockWorldMap) RuinCity(name string) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RuinCity", name)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// RuinCity indicates an expected call of RuinCity.
+func (mr *MockWorldMapMockRecorder) RuinCity(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RuinCity", reflect.TypeOf((*MockWorldMap)(nil).RuinCity), name)
+}
+
+// SetMetadata mocks base method.
+func (m *MockWorldMap) SetMetadata(city, key, value string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMetadata", city, key, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMetadata indicates an expected call of SetMetadata.
+func (mr *MockWorldMapMockRecorder) SetMetadata(city, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMetadata", reflect.TypeOf((*MockWorldMap)(nil).SetMetadata), city, key, value)
+}
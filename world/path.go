@@ -0,0 +1,116 @@
+package world
+
+import (
+	"container/heap"
+)
+
+// ShortestPath returns directions of the shortest route along open roads from one city to another
+// on the current map, road lengths are used as distances. Among routes of the same length the one
+// entering the target from the alphabetically smallest city is preferred, and so on back to the start.
+// It returns an empty route if both cities are the same and nil if there is no route or any of the cities
+// doesn't exist.
+func ShortestPath(worldMap WorldMap, from string, to string) []string {
+	cities := worldMap.GetCities()
+	if cities[from] == nil || cities[to] == nil {
+		return nil
+	}
+	type step struct {
+		previous  string
+		direction string
+	}
+	distances := map[string]uint32{from: 0}
+	steps := make(map[string]step)
+	visited := make(map[string]bool)
+	queue := &pathQueue{{city: from}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(pathNode)
+		if visited[current.city] {
+			continue
+		}
+		visited[current.city] = true
+		if current.city == to {
+			break
+		}
+		city := cities[current.city]
+		for _, direction := range city.GetDirections() {
+			road := city.Roads[direction]
+			next := road.To.Name
+			distance := current.distance + road.Length
+			if known, ok := distances[next]; visited[next] || ok && (known < distance || known == distance && steps[next].previous < current.city) {
+				continue
+			}
+			distances[next] = distance
+			steps[next] = step{previous: current.city, direction: direction}
+			heap.Push(queue, pathNode{city: next, distance: distance})
+		}
+	}
+	if !visited[to] {
+		return nil
+	}
+	route := make([]string, 0)
+	for city := to; city != from; city = steps[city].previous {
+		route = append([]string{steps[city].direction}, route...)
+	}
+	return route
+}
+
+// Reachable returns names of the cities which can be reached along open roads from the given city,
+// including the city itself. It returns an empty set if the city doesn't exist.
+func Reachable(worldMap WorldMap, from string) map[string]bool {
+	cities := worldMap.GetCities()
+	reachable := make(map[string]bool)
+	if cities[from] == nil {
+		return reachable
+	}
+	reachable[from] = true
+	queue := []string{from}
+	for len(queue) > 0 {
+		city := cities[queue[0]]
+		queue = queue[1:]
+		for _, direction := range city.GetDirections() {
+			if next := city.Roads[direction].To.Name; !reachable[next] {
+				reachable[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return reachable
+}
+
+// FollowPath returns the city reached by following the directions from the given city
+// or an empty string if any of the roads doesn't exist or is closed.
+func FollowPath(worldMap WorldMap, from string, route []string) string {
+	city := worldMap.GetCities()[from]
+	for _, direction := range route {
+		if city == nil || city.Roads[direction] == nil {
+			return ""
+		}
+		city = city.Roads[direction].To
+	}
+	if city == nil {
+		return ""
+	}
+	return city.Name
+}
+
+// pathNode is a city reached by the path search at given distance.
+type pathNode struct {
+	city     string
+	distance uint32
+}
+
+// pathQueue is a priority queue of cities ordered by distance, ties are broken by city names.
+type pathQueue []pathNode
+
+func (q pathQueue) Len() int { return len(q) }
+func (q pathQueue) Less(i, j int) bool {
+	return q[i].distance < q[j].distance || q[i].distance == q[j].distance && q[i].city < q[j].city
+}
+func (q pathQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x interface{}) { *q = append(*q, x.(pathNode)) }
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	node := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return node
+}
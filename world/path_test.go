@@ -0,0 +1,66 @@
+package world
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestShortestPath(t *testing.T) {
+	wm := InitWorldMap()
+	assert.NilError(t, wm.AddRoad("A", "east", "B", RoadOptions{}))
+	assert.NilError(t, wm.AddRoad("B", "east", "C", RoadOptions{}))
+	assert.NilError(t, wm.AddRoad("A", "north", "C", RoadOptions{Length: 3}))
+	assert.NilError(t, wm.AddRoad("D", "east", "A", RoadOptions{OneWay: true}))
+	assert.DeepEqual(t, ShortestPath(wm, "A", "C"), []string{"east", "east"})
+	assert.DeepEqual(t, ShortestPath(wm, "A", "A"), []string{})
+	assert.Assert(t, FollowPath(wm, "A", []string{"east", "east"}) == "C")
+	// one-way road can't be used backwards
+	assert.Assert(t, ShortestPath(wm, "A", "D") == nil)
+	assert.DeepEqual(t, ShortestPath(wm, "D", "B"), []string{"east", "east"})
+
+	assert.NilError(t, wm.CloseRoad("B", "east"))
+	assert.Assert(t, FollowPath(wm, "A", []string{"east", "east"}) == "")
+	assert.DeepEqual(t, ShortestPath(wm, "A", "C"), []string{"north"})
+	wm.RuinCity("A")
+	assert.Assert(t, ShortestPath(wm, "B", "C") == nil)
+	assert.Assert(t, ShortestPath(wm, "A", "C") == nil)
+}
+
+func TestShortestPathTies(t *testing.T) {
+	wm := InitWorldMap()
+	assert.NilError(t, wm.AddRoad("A", "east", "Z", RoadOptions{}))
+	assert.NilError(t, wm.AddRoad("Z", "north", "T", RoadOptions{Length: 2}))
+	assert.NilError(t, wm.AddRoad("A", "north", "B", RoadOptions{Length: 2}))
+	assert.NilError(t, wm.AddRoad("B", "east", "T", RoadOptions{}))
+	// both routes are 3 long, Z is searched first but T is entered from B
+	assert.DeepEqual(t, ShortestPath(wm, "A", "T"), []string{"north", "east"})
+}
+
+func TestReachable(t *testing.T) {
+	wm := InitWorldMap()
+	assert.NilError(t, wm.AddRoad("A", "east", "B", RoadOptions{}))
+	assert.NilError(t, wm.AddRoad("B", "east", "C", RoadOptions{OneWay: true}))
+	assert.NilError(t, wm.AddRoad("C", "north", "D", RoadOptions{}))
+	assert.NilError(t, wm.CloseRoad("C", "north"))
+	assert.DeepEqual(t, Reachable(wm, "A"), map[string]bool{"A": true, "B": true, "C": true})
+	assert.DeepEqual(t, Reachable(wm, "C"), map[string]bool{"C": true})
+	assert.DeepEqual(t, Reachable(wm, "X"), map[string]bool{})
+}
+
+func TestMoveAlienAlong(t *testing.T) {
+	wm := InitWorldMap()
+	assert.NilError(t, wm.AddRoad("A", "east", "B", RoadOptions{}))
+	assert.NilError(t, wm.AddRoad("A", "north", "C", RoadOptions{Length: 2}))
+	alien := &Alien{Name: "X", City: "A"}
+	assert.NilError(t, wm.AddAlien(alien))
+	assert.Error(t, wm.MoveAlienAlong(alien, "west"), "no cities in west direction")
+	assert.NilError(t, wm.MoveAlienAlong(alien, "east"))
+	assert.Assert(t, alien.City == "B" && wm.GetCities()["B"].Aliens["X"])
+	assert.NilError(t, wm.MoveAlienAlong(alien, "west"))
+	assert.NilError(t, wm.MoveAlienAlong(alien, "north"))
+	assert.Assert(t, alien.Transit != nil && alien.Transit.To == "C")
+	// the direction is ignored in transit
+	assert.NilError(t, wm.MoveAlienAlong(alien, "east"))
+	assert.Assert(t, alien.Transit == nil && alien.City == "C")
+}
@@ -0,0 +1,101 @@
+package world
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Render draws the world as a grid laid out by Layout. Cities are shown with amount of aliens
+// in them, e.g. Foo(2), ruins as #Foo. Roads between cities in neighbour cells are drawn with - and |,
+// one-way roads with arrows and closed roads with x. Diagonal roads aren't drawn, on hexagonal maps
+// north-western and south-eastern roads are drawn vertically.
+func Render(worldMap WorldMap) (string, error) {
+	positions, err := Layout(worldMap)
+	if err != nil {
+		return "", err
+	}
+	cities := worldMap.GetCities()
+	labels := make(map[Point]string, len(positions))
+	names := make(map[Point]string, len(positions))
+	width, right, bottom := 0, 0, 0
+	for name, position := range positions {
+		label := "#" + name
+		if city := cities[name]; city != nil {
+			label = name
+			if len(city.Aliens) > 0 {
+				label += fmt.Sprintf("(%d)", len(city.Aliens))
+			}
+		}
+		labels[position], names[position] = label, name
+		if len(label) > width {
+			width = len(label)
+		}
+		if position.X > right {
+			right = position.X
+		}
+		if position.Y > bottom {
+			bottom = position.Y
+		}
+	}
+	cityAt := func(x int, y int) *City {
+		return cities[names[Point{x, y}]]
+	}
+	// roads going one cell to the east or to the south are drawn, whatever their directions are named
+	var east, west, south, north string
+	for direction, offset := range offsetsOf(worldMap.GetTopology()) {
+		switch offset {
+		case Point{1, 0}:
+			east = direction
+		case Point{-1, 0}:
+			west = direction
+		case Point{0, 1}:
+			south = direction
+		case Point{0, -1}:
+			north = direction
+		}
+	}
+	var output strings.Builder
+	for y := 0; y <= bottom; y++ {
+		var line, roads strings.Builder
+		for x := 0; x <= right; x++ {
+			city := cityAt(x, y)
+			label := labels[Point{x, y}]
+			mark := roadMark(city, east, cityAt(x+1, y), west, [4]string{"---", "-->", "<--", "-x-"}, "   ")
+			// roads fill the rest of the cell to reach the neighbour
+			padding := " "
+			if mark != "   " {
+				padding = "-"
+			}
+			line.WriteString(label + strings.Repeat(padding, width-len(label)) + mark)
+			mark = roadMark(city, south, cityAt(x, y+1), north, [4]string{"|", "v", "^", "x"}, " ")
+			roads.WriteString(fmt.Sprintf("%-*s", width+3, mark))
+		}
+		output.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+		if y < bottom {
+			output.WriteString(strings.TrimRight(roads.String(), " ") + "\n")
+		}
+	}
+	return output.String(), nil
+}
+
+// roadMark returns the mark of roads between two neighbour cities: the first mark for
+// two-way roads, then one-way roads forward and backward, then closed roads.
+func roadMark(from *City, direction string, to *City, opposite string, marks [4]string, none string) string {
+	if from == nil || to == nil {
+		return none
+	}
+	forward := from.Roads[direction] != nil && from.Roads[direction].To == to
+	backward := to.Roads[opposite] != nil && to.Roads[opposite].To == from
+	switch {
+	case forward && backward:
+		return marks[0]
+	case forward:
+		return marks[1]
+	case backward:
+		return marks[2]
+	case from.Closed[direction] != nil && from.Closed[direction].To == to,
+		to.Closed[opposite] != nil && to.Closed[opposite].To == from:
+		return marks[3]
+	}
+	return none
+}
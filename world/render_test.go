@@ -0,0 +1,36 @@
+package world
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRender(t *testing.T) {
+	wm, err := ParseASCIIMap([]string{
+		"A - B",
+		"|   |",
+		"C   D - F",
+		"    |",
+		"    E",
+	}, CompassTopology)
+	assert.NilError(t, err)
+	wm.AddRoad("F", "east", "G", RoadOptions{OneWay: true})
+	wm.AddRoad("G", "north", "H", RoadOptions{OneWay: true})
+	wm.AddCity("Isle", nil)
+	wm.CloseRoad("A", "south")
+	wm.AddAlien(&Alien{Name: "X", City: "B"})
+	wm.AddAlien(&Alien{Name: "Y", City: "B"})
+	wm.AddAlien(&Alien{Name: "Z", City: "D"})
+	wm.AddAlien(&Alien{Name: "U", City: "E"})
+	wm.AddAlien(&Alien{Name: "V", City: "E"})
+	wm.DestroyCity("E")
+	output, err := Render(wm)
+	assert.NilError(t, err)
+	assert.Equal(t, output, ""+
+		"A------B(2)          H             Isle\n"+
+		"x      |             ^\n"+
+		"C      D(1)---F----->G\n"+
+		"\n"+
+		"       #E\n")
+}
@@ -0,0 +1,121 @@
+package world
+
+import (
+	"fmt"
+	"log"
+	"sort"
+)
+
+// Ruin keeps everything required to rebuild a destroyed city.
+type Ruin struct {
+	Name     string
+	Metadata map[string]string
+	// Links are roads leading from and into the city at the moment of its destruction.
+	Links []Link
+}
+
+// Link is a one-way road or portal between two cities remembered by a ruin.
+type Link struct {
+	From string
+	// Direction of the road or name of the portal.
+	Direction string
+	To        string
+	Weight    float64
+	Length    uint32
+	Closed    bool
+	Portal    bool
+	Chance    float64
+}
+
+// other returns the end of the link which is not the given city.
+func (l Link) other(city string) string {
+	if l.From == city {
+		return l.To
+	}
+	return l.From
+}
+
+func (m *worldMapImpl) GetRuins() map[string]*Ruin {
+	return m.Ruins
+}
+
+// bury remembers the city and all roads and portals leading from and into it before the city is destroyed.
+func (m *worldMapImpl) bury(city *City) {
+	ruin := &Ruin{Name: city.Name, Metadata: city.Metadata}
+	for _, closed := range []bool{false, true} {
+		for direction, road := range city.roads(closed) {
+			ruin.Links = append(ruin.Links, Link{From: city.Name, Direction: direction, To: road.To.Name, Weight: road.Weight, Length: road.Length, Closed: closed})
+		}
+		for _, other := range m.Cities {
+			for direction, road := range other.roads(closed) {
+				if road.To == city && other != city {
+					ruin.Links = append(ruin.Links, Link{From: other.Name, Direction: direction, To: city.Name, Weight: road.Weight, Length: road.Length, Closed: closed})
+				}
+			}
+		}
+	}
+	for name, portal := range city.Portals {
+		ruin.Links = append(ruin.Links, Link{From: city.Name, Direction: name, To: portal.To.Name, Portal: true, Chance: portal.Chance})
+	}
+	for _, other := range m.Cities {
+		for name, portal := range other.Portals {
+			if portal.To == city && other != city {
+				ruin.Links = append(ruin.Links, Link{From: other.Name, Direction: name, To: city.Name, Portal: true, Chance: portal.Chance})
+			}
+		}
+	}
+	sortLinks(ruin.Links)
+	m.Ruins[city.Name] = ruin
+}
+
+func (m *worldMapImpl) RebuildCity(name string) error {
+	ruin := m.Ruins[name]
+	if ruin == nil {
+		return fmt.Errorf("there are no ruins of city %s", name)
+	}
+	if m.Cities[name] != nil {
+		return fmt.Errorf("city %s already exists", name)
+	}
+	delete(m.Ruins, name)
+	city := m.getOrCreateCity(name)
+	for key, value := range ruin.Metadata {
+		city.Metadata[key] = value
+	}
+	for _, link := range ruin.Links {
+		neighbour := link.other(name)
+		if m.Cities[neighbour] == nil {
+			// the neighbour is ruined as well so it restores the road when it's rebuilt
+			if other := m.Ruins[neighbour]; other != nil {
+				other.Links = append(other.Links, link)
+				sortLinks(other.Links)
+			}
+			continue
+		}
+		from, to := m.Cities[link.From], m.Cities[link.To]
+		if link.Portal {
+			if from.Portals[link.Direction] == nil {
+				from.Portals[link.Direction] = &Portal{To: to, Chance: link.Chance}
+			}
+			continue
+		}
+		if from.Roads[link.Direction] != nil || from.Closed[link.Direction] != nil {
+			// the direction has been taken by another road meanwhile
+			continue
+		}
+		from.setRoad(link.Direction, &Road{To: to, Weight: link.Weight, Length: link.Length}, link.Closed)
+	}
+	log.Printf("%s has been rebuilt", name)
+	return nil
+}
+
+func sortLinks(links []Link) {
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].From != links[j].From {
+			return links[i].From < links[j].From
+		}
+		if links[i].Direction != links[j].Direction {
+			return links[i].Direction < links[j].Direction
+		}
+		return !links[i].Portal && links[j].Portal
+	})
+}
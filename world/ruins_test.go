@@ -0,0 +1,56 @@
+package world
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRebuildCity(t *testing.T) {
+	wm := InitWorldMap()
+	assert.NilError(t, wm.AddRoad("A", "east", "B", RoadOptions{Weight: 2, Length: 3}))
+	assert.NilError(t, wm.AddRoad("C", "north", "B", RoadOptions{OneWay: true}))
+	assert.NilError(t, wm.SetMetadata("B", "population", "100"))
+	wm.AddAlien(&Alien{Name: "X", City: "B"})
+	wm.AddAlien(&Alien{Name: "Y", City: "B"})
+	wm.DestroyCity("B")
+	assert.Assert(t, wm.GetCities()["B"] == nil)
+	ruin := wm.GetRuins()["B"]
+	assert.DeepEqual(t, ruin.Links, []Link{
+		{From: "A", Direction: "east", To: "B", Weight: 2, Length: 3},
+		{From: "B", Direction: "west", To: "A", Weight: 2, Length: 3},
+		{From: "C", Direction: "north", To: "B", Weight: 1, Length: 1},
+	})
+
+	assert.NilError(t, wm.RebuildCity("B"))
+	assert.Assert(t, wm.GetRuins()["B"] == nil)
+	b := wm.GetCities()["B"]
+	assert.Assert(t, len(b.Aliens) == 0)
+	assert.Assert(t, b.Metadata["population"] == "100")
+	assert.Assert(t, b.IsTwoWay("west"))
+	assert.Assert(t, b.Roads["west"].Weight == 2 && b.Roads["west"].Length == 3)
+	assert.Assert(t, wm.GetCities()["C"].Roads["north"].To == b)
+	assert.Assert(t, b.Roads["south"] == nil)
+
+	assert.Error(t, wm.RebuildCity("B"), "there are no ruins of city B")
+	assert.Error(t, wm.RebuildCity("Atlantis"), "there are no ruins of city Atlantis")
+}
+
+func TestRebuildCityRuinedNeighbour(t *testing.T) {
+	wm := InitWorldMap()
+	wm.AddCity("A", map[string]string{"east": "B"})
+	wm.AddCity("B", map[string]string{"east": "C"})
+	destroy := func(city string) {
+		wm.AddAlien(&Alien{Name: city + "1", City: city})
+		wm.AddAlien(&Alien{Name: city + "2", City: city})
+		wm.DestroyCity(city)
+	}
+	destroy("A")
+	destroy("B")
+	// B is still ruined when A is rebuilt, so the road between them is restored together with B
+	assert.NilError(t, wm.RebuildCity("A"))
+	assert.Assert(t, len(wm.GetCities()["A"].Roads) == 0)
+	assert.NilError(t, wm.RebuildCity("B"))
+	assert.Assert(t, wm.GetCities()["A"].IsTwoWay("east"))
+	assert.Assert(t, wm.GetCities()["B"].IsTwoWay("east"))
+}
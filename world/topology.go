@@ -0,0 +1,110 @@
+package world
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Topology describes the vocabulary of directions used by the world map.
+// Every direction has an opposite one which is used to create reciprocal roads
+// and to unlink neighbours when a city is destroyed.
+type Topology struct {
+	// Directions lists all directions in the order they are reported and written.
+	Directions []string
+	opposites  map[string]string
+}
+
+var (
+	// CompassTopology is the classic 4 directions world.
+	CompassTopology = mustTopology([]string{"east", "north", "west", "south"},
+		[2]string{"east", "west"}, [2]string{"north", "south"})
+	// Compass8Topology adds diagonal directions to the compass.
+	Compass8Topology = mustTopology([]string{"east", "northeast", "north", "northwest", "west", "southwest", "south", "southeast"},
+		[2]string{"east", "west"}, [2]string{"north", "south"},
+		[2]string{"northeast", "southwest"}, [2]string{"northwest", "southeast"})
+	// HexTopology describes a hexagonal grid with 6 directions.
+	HexTopology = mustTopology([]string{"east", "northeast", "northwest", "west", "southwest", "southeast"},
+		[2]string{"east", "west"}, [2]string{"northeast", "southwest"}, [2]string{"northwest", "southeast"})
+	// CubeTopology is the compass extended with up and down directions for 3D worlds.
+	CubeTopology = mustTopology([]string{"east", "north", "west", "south", "up", "down"},
+		[2]string{"east", "west"}, [2]string{"north", "south"}, [2]string{"up", "down"})
+)
+
+var namedTopologies = map[string]*Topology{
+	"compass":  CompassTopology,
+	"compass8": Compass8Topology,
+	"hex":      HexTopology,
+	"cube":     CubeTopology,
+}
+
+// NewTopology creates a topology from the ordered list of directions and pairs of
+// opposite directions. Every direction must be listed once and belong to exactly one pair.
+func NewTopology(directions []string, pairs ...[2]string) (*Topology, error) {
+	topology := &Topology{Directions: directions, opposites: make(map[string]string)}
+	for _, pair := range pairs {
+		for i, direction := range pair {
+			if direction == "" || strings.ContainsAny(direction, " =") {
+				return nil, fmt.Errorf("invalid direction name %q", direction)
+			}
+			if _, exists := topology.opposites[direction]; exists {
+				return nil, fmt.Errorf("direction %s is defined more than once", direction)
+			}
+			topology.opposites[direction] = pair[1-i]
+		}
+	}
+	if len(directions) != len(topology.opposites) {
+		return nil, fmt.Errorf("expected %d directions in pairs but got %d", len(directions), len(topology.opposites))
+	}
+	listed := make(map[string]bool, len(directions))
+	for _, direction := range directions {
+		if listed[direction] {
+			return nil, fmt.Errorf("direction %s is listed more than once", direction)
+		}
+		listed[direction] = true
+		if _, exists := topology.opposites[direction]; !exists {
+			return nil, fmt.Errorf("direction %s has no opposite", direction)
+		}
+	}
+	return topology, nil
+}
+
+// ParseTopology returns one of the named topologies (compass, compass8, hex, cube)
+// or creates a new one from a comma separated list of opposite pairs,
+// e.g. "east:west,north:south,up:down".
+func ParseTopology(spec string) (*Topology, error) {
+	if topology, ok := namedTopologies[spec]; ok {
+		return topology, nil
+	}
+	directions := make([]string, 0)
+	pairs := make([][2]string, 0)
+	for _, pairSpec := range strings.Split(spec, ",") {
+		pair := strings.Split(pairSpec, ":")
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("expected direction:opposite format but got %s", pairSpec)
+		}
+		directions = append(directions, pair[0], pair[1])
+		pairs = append(pairs, [2]string{pair[0], pair[1]})
+	}
+	return NewTopology(directions, pairs...)
+}
+
+// Opposite returns the opposite of the given direction and false
+// if the direction is not a part of the topology.
+func (t *Topology) Opposite(direction string) (string, bool) {
+	opposite, ok := t.opposites[direction]
+	return opposite, ok
+}
+
+// Has checks whether the direction is a part of the topology.
+func (t *Topology) Has(direction string) bool {
+	_, ok := t.opposites[direction]
+	return ok
+}
+
+func mustTopology(directions []string, pairs ...[2]string) *Topology {
+	topology, err := NewTopology(directions, pairs...)
+	if err != nil {
+		panic(err)
+	}
+	return topology
+}
@@ -0,0 +1,47 @@
+package world
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestBuiltinTopologies(t *testing.T) {
+	for _, topology := range []*Topology{CompassTopology, Compass8Topology, HexTopology, CubeTopology} {
+		for _, direction := range topology.Directions {
+			opposite, ok := topology.Opposite(direction)
+			assert.Assert(t, ok)
+			back, _ := topology.Opposite(opposite)
+			assert.Assert(t, back == direction)
+		}
+	}
+	assert.Assert(t, len(HexTopology.Directions) == 6)
+	assert.Assert(t, CubeTopology.Has("up"))
+	assert.Assert(t, !CompassTopology.Has("up"))
+}
+
+func TestNewTopology(t *testing.T) {
+	_, err := NewTopology([]string{"up", "down", "left"}, [2]string{"up", "down"})
+	assert.Error(t, err, "expected 3 directions in pairs but got 2")
+	_, err = NewTopology([]string{"up", "down"}, [2]string{"up", "down"}, [2]string{"up", "left"})
+	assert.Error(t, err, "direction up is defined more than once")
+	_, err = NewTopology([]string{"up", "down"}, [2]string{"up", "left"})
+	assert.Error(t, err, "direction down has no opposite")
+	_, err = NewTopology([]string{"up", "do wn"}, [2]string{"up", "do wn"})
+	assert.Error(t, err, `invalid direction name "do wn"`)
+	_, err = NewTopology([]string{"east", "east"}, [2]string{"east", "west"})
+	assert.Error(t, err, "direction east is listed more than once")
+}
+
+func TestParseTopology(t *testing.T) {
+	topology, err := ParseTopology("hex")
+	assert.NilError(t, err)
+	assert.Assert(t, topology == HexTopology)
+	topology, err = ParseTopology("left:right,up:down")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, topology.Directions, []string{"left", "right", "up", "down"})
+	opposite, _ := topology.Opposite("right")
+	assert.Assert(t, opposite == "left")
+	_, err = ParseTopology("left-right")
+	assert.Error(t, err, "expected direction:opposite format but got left-right")
+}